@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/krzysdabro/tlscert/internal/certutil"
+	"github.com/spf13/pflag"
+)
+
+// asn1Dump prints DER or PEM objects from a file as ASN.1 trees.
+func asn1Dump() {
+	if pflag.NArg() != 2 {
+		pflag.Usage()
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(pflag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to read file:", err)
+		os.Exit(1)
+	}
+
+	blocks := []*pem.Block{}
+	for rest := data; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		blocks = append(blocks, &pem.Block{Bytes: data})
+	}
+
+	for i, block := range blocks {
+		if i > 0 {
+			fmt.Println()
+		}
+		if block.Type != "" {
+			fmt.Printf("-----%s-----\n", block.Type)
+		}
+
+		tree, err := certutil.FormatASN1(block.Bytes)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to parse ASN.1:", err)
+			os.Exit(1)
+		}
+		fmt.Println(tree)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/spf13/pflag"
+)
+
+// ctSearch lists certificates issued for a name as found in CT logs,
+// or prints one of them when --ct-open is set.
+func ctSearch(opts *internal.PrintOptions) {
+	if *fCTOpen != 0 {
+		cert, err := internal.GetCTSearchCertificate(*fCTEndpoint, *fCTOpen)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to get certificate:", err)
+			os.Exit(1)
+		}
+
+		printCertificate(cert, opts)
+		return
+	}
+
+	if pflag.NArg() != 2 {
+		pflag.Usage()
+		os.Exit(1)
+	}
+
+	entries, err := internal.SearchCT(*fCTEndpoint, pflag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to search CT logs:", err)
+		os.Exit(1)
+	}
+
+	internal.PrintCTSearchResults(entries, opts)
+}
@@ -1,12 +1,18 @@
 package internal
 
 import (
+	"context"
+	"crypto"
+	_ "crypto/sha1" // register SHA-1 for fingerprints
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/base64"
 	"fmt"
 	"math/big"
 	"net"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,31 +20,20 @@ import (
 	"github.com/krzysdabro/tlscert/internal/certutil"
 )
 
-var (
-	wildcardPolicy = "2.5.29.32.0"
-	knownPolicies  = map[string]string{
-		"2.23.140.1.1":     "Extended Validation",
-		"2.23.140.1.2.1":   "Domain Validated",
-		"2.23.140.1.2.2":   "Organizational Validation",
-		"2.23.140.1.2.3":   "Individual Validation",
-		"2.23.140.1.3":     "EV Code Signing Certificate",
-		"2.23.140.1.4.1":   "Code Signing Certificate",
-		"2.23.140.1.4.2":   "Timestamp Certificate",
-		"0.4.0.194112.1.0": "ETSI QCP-n",
-		"0.4.0.194112.1.1": "ETSI QCP-l",
-		"0.4.0.194112.1.2": "ETSI QCP-n-qscd",
-		"0.4.0.194112.1.3": "ETSI QCP-l-qscd",
-		"0.4.0.194112.1.4": "ETSI QEVCP-w",
-		"0.4.0.194112.1.5": "ETSI QNCP-w",
-		"0.4.0.194112.1.6": "ETSI QNCP-w-gen",
-	}
-)
+var wildcardPolicy = "2.5.29.32.0"
 
 // Certificate defines a X.509 certificate and its chain.
 type Certificate struct {
 	cert     *x509.Certificate
 	chain    map[string]*Certificate
 	hostname string
+
+	// servedOverTLS reports whether the certificate was obtained in a TLS handshake.
+	servedOverTLS bool
+	ocspStaple    []byte
+	tlsSCTs       [][]byte
+	// fromAIA reports whether the certificate was downloaded from the Authority Information Access URL.
+	fromAIA bool
 }
 
 // NewCertificate creates a new certificate.
@@ -79,24 +74,59 @@ func (c *Certificate) DownloadIssuingCertificate() {
 		}
 
 		if issuingCert, err := GetCertificate(u); err == nil {
+			issuingCert.fromAIA = true
 			issuingCert.DownloadIssuingCertificate()
 			c.AddCertificateToChain(issuingCert)
 		}
 	}
 }
 
+// VerifyOptions defines certificate verification options.
+type VerifyOptions struct {
+	// CurrentTime is the time at which the certificate is verified.
+	// If zero, the current time is used.
+	CurrentTime time.Time
+
+	// Roots is the set of trusted root certificates.
+	// If nil, the system pool is used.
+	Roots *x509.CertPool
+
+	// Purpose is the intended use of the certificate.
+	// If empty, the certificate is verified for server authentication.
+	Purpose Purpose
+}
+
+func (o *VerifyOptions) currentTime() time.Time {
+	if o == nil || o.CurrentTime.IsZero() {
+		return time.Now()
+	}
+	return o.CurrentTime
+}
+
 // IsValid checks certificate validity.
-func (c *Certificate) IsValid() bool {
+func (c *Certificate) IsValid(vopts *VerifyOptions) bool {
 	opts := x509.VerifyOptions{
 		Intermediates: c.chainCertPool(),
+		CurrentTime:   vopts.currentTime(),
 	}
 
-	if c.hostname != "" {
+	purpose := Purpose("")
+	if vopts != nil {
+		opts.Roots = vopts.Roots
+		opts.KeyUsages = vopts.Purpose.extKeyUsages()
+		purpose = vopts.Purpose
+	}
+
+	// hostname is only meaningful when verifying a server certificate
+	if c.hostname != "" && (purpose == "" || purpose == PurposeServer) {
 		opts.DNSName = c.hostname
 	}
 
-	_, err := c.cert.Verify(opts)
-	return err == nil
+	if _, err := c.cert.Verify(opts); err != nil {
+		return false
+	}
+
+	return purpose == "" || len(c.PurposeMismatches(purpose)) == 0
 }
 
 // Chain returns chain of the certificate.
@@ -113,6 +143,39 @@ func (c *Certificate) Chain() map[string]*Certificate {
 	return certs
 }
 
+// OrderedChain returns chain of the certificate ordered from the issuer of
+// the certificate towards the root. Certificates not on the issuance path
+// follow, sorted by subject.
+func (c *Certificate) OrderedChain() []*Certificate {
+	chain := c.Chain()
+	result := []*Certificate{}
+	visited := map[string]bool{}
+
+	for cur := c; ; {
+		next, ok := chain[cur.Issuer().String()]
+		if !ok || visited[cur.Issuer().String()] || next.Equal(cur) {
+			break
+		}
+		visited[cur.Issuer().String()] = true
+		result = append(result, next)
+		cur = next
+	}
+
+	rest := []string{}
+	for subject := range chain {
+		if !visited[subject] {
+			rest = append(rest, subject)
+		}
+	}
+	sort.Strings(rest)
+
+	for _, subject := range rest {
+		result = append(result, chain[subject])
+	}
+
+	return result
+}
+
 // Subject returns subject of the certificate.
 func (c *Certificate) Subject() pkix.Name {
 	return c.cert.Subject
@@ -123,6 +186,21 @@ func (c *Certificate) Issuer() pkix.Name {
 	return c.cert.Issuer
 }
 
+// SubjectDN returns subject of the certificate keeping multi-valued RDNs
+// and values of any type.
+func (c *Certificate) SubjectDN() certutil.DN {
+	// already parsed by crypto/x509, which is stricter
+	dn, _ := certutil.ParseDN(c.cert.RawSubject)
+	return dn
+}
+
+// IssuerDN returns issuer of the certificate keeping multi-valued RDNs
+// and values of any type.
+func (c *Certificate) IssuerDN() certutil.DN {
+	dn, _ := certutil.ParseDN(c.cert.RawIssuer)
+	return dn
+}
+
 // CommonName returns common name of the certificate.
 func (c *Certificate) CommonName() string {
 	return c.cert.Subject.CommonName
@@ -133,6 +211,11 @@ func (c *Certificate) SignatureAlgorithm() string {
 	return c.cert.SignatureAlgorithm.String()
 }
 
+// PublicKey returns details of the subject public key.
+func (c *Certificate) PublicKey() *certutil.PublicKeyInfo {
+	return certutil.GetPublicKeyInfo(c.cert)
+}
+
 // KeyUsage returns a set of valid usages for the key.
 func (c *Certificate) KeyUsage() string {
 	ku := c.cert.KeyUsage
@@ -154,27 +237,49 @@ func (c *Certificate) ExtKeyUsage() string {
 	for _, ku := range c.cert.ExtKeyUsage {
 		result = append(result, ku.String())
 	}
+	for _, oid := range c.cert.UnknownExtKeyUsage {
+		result = append(result, certutil.OIDNameOrString(oid))
+	}
 
 	return strings.Join(result, "\n")
 }
 
-// CertificatePolicies returns policies applied to the certificate, with known
-// OIDs replaced by their names and the anyPolicy OID omitted.
+// CertificatePolicies returns policies applied to the certificate, with OIDs
+// from the registry replaced by their names, followed by their CPS URIs and
+// user notices. The anyPolicy OID is omitted unless it has qualifiers.
 func (c *Certificate) CertificatePolicies() string {
-	result := []string{}
+	policies, err := certutil.GetCertificatePolicies(c.cert)
+	if err != nil {
+		return err.Error()
+	}
 
-	for _, oid := range c.cert.Policies {
-		s := oid.String()
-		if s == wildcardPolicy {
+	result := []string{}
+	for _, p := range policies {
+		if p.ID.String() == wildcardPolicy && len(p.CPSURIs) == 0 && len(p.UserNotices) == 0 {
 			continue
 		}
 
-		if name, ok := knownPolicies[s]; ok {
-			result = append(result, name)
-			continue
+		qualifiers := []string{}
+		for _, uri := range p.CPSURIs {
+			qualifiers = append(qualifiers, "CPS: "+uri)
+		}
+		for _, notice := range p.UserNotices {
+			if notice.ExplicitText != "" {
+				qualifiers = append(qualifiers, "User Notice: "+notice.ExplicitText)
+			}
+			if notice.Organization != "" || len(notice.NoticeNumbers) > 0 {
+				numbers := make([]string, len(notice.NoticeNumbers))
+				for i, n := range notice.NoticeNumbers {
+					numbers[i] = fmt.Sprintf("#%d", n)
+				}
+				qualifiers = append(qualifiers, fmt.Sprintf("Notice Reference: %s %s", notice.Organization, strings.Join(numbers, ", ")))
+			}
 		}
 
-		result = append(result, s)
+		result = append(result, certutil.OIDNameOrString(p.ID))
+		if len(qualifiers) > 0 {
+			result = append(result, indentText(strings.Join(qualifiers, "\n"), 1))
+		}
 	}
 
 	return strings.Join(result, "\n")
@@ -200,9 +305,129 @@ func (c *Certificate) NotAfter() time.Time {
 	return c.cert.NotAfter
 }
 
-// SignedCertificateTimestamps returns SCTs of the certificate.
-func (c *Certificate) SignedCertificateTimestamps() []ct.SignedCertificateTimestamp {
-	return certutil.GetSCTs(c.cert)
+// SignedCertificateTimestamps returns SCTs of the certificate: embedded in it,
+// delivered in the TLS extension and in the stapled OCSP response.
+func (c *Certificate) SignedCertificateTimestamps() []certutil.SCT {
+	result := []certutil.SCT{}
+
+	sources := []struct {
+		scts   []ct.SignedCertificateTimestamp
+		source certutil.SCTSource
+	}{
+		{certutil.GetSCTs(c.cert), certutil.SCTEmbedded},
+		{certutil.ParseSCTs(c.tlsSCTs), certutil.SCTFromTLS},
+		{certutil.GetOCSPSCTs(c.ocspStaple), certutil.SCTFromOCSP},
+	}
+
+	for _, s := range sources {
+		for _, sct := range s.scts {
+			result = append(result, certutil.SCT{SignedCertificateTimestamp: sct, Source: s.source})
+		}
+	}
+
+	return result
+}
+
+// VerifySCT verifies the signature of a SCT of the certificate.
+func (c *Certificate) VerifySCT(sct certutil.SCT) (certutil.SCTStatus, error) {
+	var issuer *x509.Certificate
+	if i, ok := c.chain[c.Issuer().String()]; ok {
+		issuer = i.cert
+	}
+
+	return certutil.VerifySCT(sct.SignedCertificateTimestamp, c.cert, issuer, sct.Source == certutil.SCTEmbedded)
+}
+
+// VerifySCTInclusion verifies the certificate is included in the log which
+// issued the SCT. If logURL or logKey are empty, the URL and the key from
+// the log list are used.
+func (c *Certificate) VerifySCTInclusion(sct certutil.SCT, logURL string, logKey []byte) (*certutil.InclusionProof, error) {
+	var issuer *x509.Certificate
+	if i, ok := c.chain[c.Issuer().String()]; ok {
+		issuer = i.cert
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return certutil.VerifyInclusion(ctx, sct.SignedCertificateTimestamp, c.cert, issuer, sct.Source == certutil.SCTEmbedded, logURL, logKey)
+}
+
+// CTCompliance evaluates Chrome and Apple Certificate Transparency policies
+// at a given time, counting only SCTs with a verified signature. Policies
+// not met by verified SCTs are unknown when some SCTs are unverifiable.
+func (c *Certificate) CTCompliance(at time.Time) []*certutil.CTPolicyResult {
+	verified := []certutil.SCT{}
+	unverifiable := 0
+	for _, sct := range c.SignedCertificateTimestamps() {
+		switch status, _ := c.VerifySCT(sct); status {
+		case certutil.SCTVerified:
+			verified = append(verified, sct)
+		case certutil.SCTUnverifiable:
+			unverifiable++
+		}
+	}
+
+	results := []*certutil.CTPolicyResult{
+		certutil.ChromeCTPolicy.Check(c.cert, verified, at),
+		certutil.AppleCTPolicy.Check(c.cert, verified, at),
+	}
+	for _, r := range results {
+		if !r.Compliant && unverifiable > 0 {
+			r.Unknown = true
+			r.Reasons = append([]string{fmt.Sprintf("%d SCTs could not be verified (issuer not present in chain)", unverifiable)}, r.Reasons...)
+		}
+	}
+	return results
+}
+
+// IsPrecertificate reports whether the certificate is a CT precertificate.
+func (c *Certificate) IsPrecertificate() bool {
+	return certutil.IsPrecertificate(c.cert)
+}
+
+// MatchPrecertificate reports whether the certificate was issued from the precertificate.
+func (c *Certificate) MatchPrecertificate(precert *Certificate) (bool, error) {
+	return certutil.MatchPrecertificate(c.cert, precert.cert)
+}
+
+// VerifyPrecertificateSCT verifies whether the SCT was issued for the precertificate.
+func (c *Certificate) VerifyPrecertificateSCT(precert *Certificate, sct certutil.SCT) (certutil.SCTStatus, error) {
+	var issuer *x509.Certificate
+	if i, ok := c.chain[c.Issuer().String()]; ok {
+		issuer = i.cert
+	}
+
+	return certutil.VerifySCT(sct.SignedCertificateTimestamp, precert.cert, issuer, false)
+}
+
+// Fingerprint returns a hash of the DER-encoded certificate.
+func (c *Certificate) Fingerprint(h crypto.Hash) []byte {
+	hash := h.New()
+	hash.Write(c.cert.Raw)
+	return hash.Sum(nil)
+}
+
+// SPKIHash returns base64-encoded SHA-256 hash of the subject public key
+// info, as used for public key pinning.
+func (c *Certificate) SPKIHash() string {
+	sum := sha256.Sum256(c.cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// SubjectKeyID returns the Subject Key Identifier of the certificate.
+func (c *Certificate) SubjectKeyID() []byte {
+	return c.cert.SubjectKeyId
+}
+
+// AuthorityKeyID returns the Authority Key Identifier of the certificate.
+func (c *Certificate) AuthorityKeyID() []byte {
+	return c.cert.AuthorityKeyId
+}
+
+// IsCA reports whether the certificate belongs to a certificate authority.
+func (c *Certificate) IsCA() bool {
+	return c.cert.IsCA
 }
 
 // SerialNumber returns the certificate's serial number.
@@ -210,27 +435,35 @@ func (c *Certificate) SerialNumber() *big.Int {
 	return c.cert.SerialNumber
 }
 
-func (c *Certificate) QCStatement() string {
+// QCStatements returns qualified certificate statements of the certificate.
+func (c *Certificate) QCStatements() ([]certutil.QCStatement, error) {
 	for _, e := range c.cert.Extensions {
-		if !e.Id.Equal(certutil.OIDQCStatementsExt) {
-			continue
+		if e.Id.Equal(certutil.OIDQCStatementsExt) {
+			return certutil.ParseQCStatement(e.Value)
 		}
+	}
+	return nil, nil
+}
 
-		statements, err := certutil.ParseQCStatement(e.Value)
-		if err != nil {
-			return err.Error()
-		}
+// QCStatement returns qualified certificate statements, one per line, with
+// directory names in a given format.
+func (c *Certificate) QCStatement(dnFormat DNFormat) string {
+	statements, err := c.QCStatements()
+	if err != nil {
+		return err.Error()
+	}
 
-		b := strings.Builder{}
-		for i, s := range statements {
-			if i > 0 {
-				b.WriteString("\n")
-			}
-			b.WriteString(s.String())
-		}
-		return b.String()
+	result := make([]string, len(statements))
+	for i, s := range statements {
+		result[i] = s.Format(dnFormat.formatInline)
 	}
-	return ""
+	return strings.Join(result, "\n")
+}
+
+// QualifiedStatus checks in trusted lists whether the certificate was issued
+// as qualified by a qualified trust service.
+func (c *Certificate) QualifiedStatus(lists []*certutil.TrustedList) *certutil.QualifiedStatus {
+	return certutil.CheckQualified(c.cert, lists)
 }
 
 // IsOCSPPresent checks whether the OCSP server URL is present in the certificate.
@@ -238,22 +471,45 @@ func (c *Certificate) IsOCSPPresent() bool {
 	return len(c.cert.OCSPServer) > 0
 }
 
-// OCSPStatus checks validity of the certificate with OCSP server.
-func (c *Certificate) OCSPStatus() (bool, error) {
+// OCSPStatus checks validity of the certificate at a given time with all
+// OCSP servers.
+func (c *Certificate) OCSPStatus(at time.Time) ([]*certutil.OCSPStatus, error) {
 	if !c.IsOCSPPresent() {
-		return false, fmt.Errorf("no OCSP server present for certificate")
+		return nil, fmt.Errorf("no OCSP server present for certificate")
 	}
 
 	issuer, issuerOk := c.chain[c.Issuer().String()]
 	if !issuerOk {
-		return false, fmt.Errorf("issuer not present in chain")
+		return nil, fmt.Errorf("issuer not present in chain")
+	}
+
+	return certutil.CheckOCSP(c.cert, issuer.cert, at)
+}
+
+// MustStaple reports whether the certificate carries the TLS Feature
+// extension requiring OCSP stapling.
+func (c *Certificate) MustStaple() bool {
+	return certutil.HasMustStaple(c.cert)
+}
+
+// IsOCSPStapled checks whether the server stapled an OCSP response.
+func (c *Certificate) IsOCSPStapled() bool {
+	return len(c.ocspStaple) > 0
+}
+
+// StapledOCSPStatus parses the OCSP response stapled by the server and
+// verifies it against the issuer and a given time.
+func (c *Certificate) StapledOCSPStatus(at time.Time) (*certutil.OCSPStatus, error) {
+	if !c.IsOCSPStapled() {
+		return nil, fmt.Errorf("no OCSP response stapled")
 	}
 
-	if ok, err := certutil.CheckOCSP(c.cert, issuer.cert); err != nil || !ok {
-		return false, err
+	issuer, issuerOk := c.chain[c.Issuer().String()]
+	if !issuerOk {
+		return nil, fmt.Errorf("issuer not present in chain")
 	}
 
-	return true, nil
+	return certutil.ParseOCSPResponse(c.ocspStaple, c.cert, issuer.cert, at)
 }
 
 // Equal reports whether the certificates are the same.
@@ -0,0 +1,143 @@
+package internal_test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+)
+
+func TestIsValid_CurrentTime(t *testing.T) {
+	tc := newTestChain(t, nil)
+	cert := tc.certificate()
+	now := time.Now()
+
+	cases := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "now", want: true},
+		{name: "before issuance", at: now.Add(-2 * time.Hour), want: false},
+		{name: "in 30 days", at: now.Add(30 * 24 * time.Hour), want: true},
+		{name: "after intermediate expiry", at: now.Add(75 * 24 * time.Hour), want: false},
+		{name: "after leaf expiry", at: now.Add(100 * 24 * time.Hour), want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := &internal.VerifyOptions{CurrentTime: c.at, Roots: tc.roots()}
+			if got := cert.IsValid(opts); got != c.want {
+				t.Fatalf("IsValid() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFingerprints(t *testing.T) {
+	cert := internal.NewCertificate(loadCert(t, os.DirFS("testdata"), "cert.pem"))
+
+	if got, want := hex.EncodeToString(cert.Fingerprint(crypto.SHA256)), "9230df22163baa4d8425b61c571955fac4f0e8b37f2c5fde846163b05cee8b01"; got != want {
+		t.Errorf("SHA-256 fingerprint = %s, want %s", got, want)
+	}
+
+	if got, want := hex.EncodeToString(cert.Fingerprint(crypto.SHA1)), "7ca59118b77d68ab1503d80742a311693fd55aea"; got != want {
+		t.Errorf("SHA-1 fingerprint = %s, want %s", got, want)
+	}
+
+	if got, want := cert.SPKIHash(), "EeIEKwf6YIadUiMKYxtLghdV6qqAbNW/5W3TT3yInIo="; got != want {
+		t.Errorf("SPKI hash = %s, want %s", got, want)
+	}
+}
+
+func TestPublicKey(t *testing.T) {
+	tc := newTestChain(t, nil)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("cannot generate key: %s", err)
+	}
+	weakRSA := rsaKey.PublicKey
+	weakRSA.E = 3
+	unitRSA := rsaKey.PublicKey
+	unitRSA.E = 1
+	evenRSA := rsaKey.PublicKey
+	evenRSA.E = 65536
+
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate key: %s", err)
+	}
+
+	cases := []struct {
+		name      string
+		pub       any
+		algorithm string
+		size      int
+		curve     string
+		errors    []string
+		warnings  []string
+	}{
+		{name: "ECDSA", pub: &tc.leafKey.PublicKey, algorithm: "ECDSA", size: 256, curve: "P-256"},
+		{name: "Ed25519", pub: edKey, algorithm: "Ed25519", size: 256},
+		{
+			name:      "weak RSA",
+			pub:       &weakRSA,
+			algorithm: "RSA",
+			size:      1024,
+			warnings:  []string{"RSA key smaller than 2048 bits", "small RSA public exponent 3"},
+		},
+		{
+			name:      "RSA exponent 1",
+			pub:       &unitRSA,
+			algorithm: "RSA",
+			size:      1024,
+			errors:    []string{"RSA public exponent 1 does not encrypt"},
+			warnings:  []string{"RSA key smaller than 2048 bits"},
+		},
+		{
+			name:      "even RSA exponent",
+			pub:       &evenRSA,
+			algorithm: "RSA",
+			size:      1024,
+			errors:    []string{"even RSA public exponent 65536"},
+			warnings:  []string{"RSA key smaller than 2048 bits"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			template := &x509.Certificate{
+				SerialNumber: big.NewInt(10),
+				Subject:      pkix.Name{CommonName: c.name},
+				NotBefore:    time.Now(),
+				NotAfter:     time.Now().Add(time.Hour),
+			}
+			cert := internal.NewCertificate(signTestCert(t, template, tc.root, c.pub, tc.rootKey))
+
+			info := cert.PublicKey()
+			if info.Algorithm != c.algorithm || info.Size != c.size || info.Curve != c.curve {
+				t.Fatalf("unexpected public key info: %+v", info)
+			}
+			if len(info.Data) == 0 {
+				t.Fatal("expected public key data")
+			}
+			if diff := cmp.Diff(c.errors, info.Errors); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(c.warnings, info.Warnings); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package certutil
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxASN1Depth limits nesting of pretty-printed ASN.1 structures.
+const maxASN1Depth = 32
+
+var asn1TagNames = map[int]string{
+	asn1.TagBoolean:         "BOOLEAN",
+	asn1.TagInteger:         "INTEGER",
+	asn1.TagBitString:       "BIT STRING",
+	asn1.TagOctetString:     "OCTET STRING",
+	asn1.TagNull:            "NULL",
+	asn1.TagOID:             "OBJECT IDENTIFIER",
+	asn1.TagEnum:            "ENUMERATED",
+	asn1.TagUTF8String:      "UTF8String",
+	asn1.TagSequence:        "SEQUENCE",
+	asn1.TagSet:             "SET",
+	asn1.TagNumericString:   "NumericString",
+	asn1.TagPrintableString: "PrintableString",
+	asn1.TagT61String:       "T61String",
+	asn1.TagIA5String:       "IA5String",
+	asn1.TagUTCTime:         "UTCTime",
+	asn1.TagGeneralizedTime: "GeneralizedTime",
+	asn1.TagGeneralString:   "GeneralString",
+	asn1.TagBMPString:       "BMPString",
+	26:                      "VisibleString",
+	28:                      "UniversalString",
+}
+
+// FormatASN1 returns DER-encoded data as an indented tree of ASN.1 values.
+// OIDs are resolved to names where known.
+func FormatASN1(der []byte) (string, error) {
+	lines, err := formatASN1(der, 0)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func formatASN1(der []byte, depth int) ([]string, error) {
+	if depth > maxASN1Depth {
+		return nil, fmt.Errorf("ASN.1 structure nested too deeply")
+	}
+
+	lines := []string{}
+	for len(der) > 0 {
+		var v asn1.RawValue
+		rest, err := asn1.Unmarshal(der, &v)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse ASN.1: %w", err)
+		}
+		der = rest
+
+		indent := strings.Repeat("  ", depth)
+		name := asn1TagName(v)
+
+		if v.IsCompound {
+			children, err := formatASN1(v.Bytes, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, indent+name)
+			lines = append(lines, children...)
+			continue
+		}
+
+		// BIT STRING and OCTET STRING often wrap DER-encoded structures
+		if nested := encapsulatedASN1(v, depth+1); nested != nil {
+			lines = append(lines, indent+name+" (encapsulates)")
+			lines = append(lines, nested...)
+			continue
+		}
+
+		// long values continue in following lines
+		value := strings.Split(formatASN1Value(v), "\n")
+		if value[0] != "" {
+			name += " " + value[0]
+		}
+		lines = append(lines, indent+name)
+		for _, line := range value[1:] {
+			lines = append(lines, indent+"  "+line)
+		}
+	}
+	return lines, nil
+}
+
+func asn1TagName(v asn1.RawValue) string {
+	switch v.Class {
+	case asn1.ClassUniversal:
+		if name, ok := asn1TagNames[v.Tag]; ok {
+			return name
+		}
+		return fmt.Sprintf("UNIVERSAL %d", v.Tag)
+	case asn1.ClassApplication:
+		return fmt.Sprintf("[APPLICATION %d]", v.Tag)
+	case asn1.ClassPrivate:
+		return fmt.Sprintf("[PRIVATE %d]", v.Tag)
+	}
+	return fmt.Sprintf("[%d]", v.Tag)
+}
+
+func encapsulatedASN1(v asn1.RawValue, depth int) []string {
+	if v.Class != asn1.ClassUniversal {
+		return nil
+	}
+
+	data := v.Bytes
+	switch v.Tag {
+	case asn1.TagBitString:
+		if len(data) < 2 || data[0] != 0 {
+			return nil
+		}
+		data = data[1:]
+	case asn1.TagOctetString:
+	default:
+		return nil
+	}
+
+	// primitive values are accepted only when they span all the data,
+	// as random bytes often parse as a short value by accident
+	var inner asn1.RawValue
+	rest, err := asn1.Unmarshal(data, &inner)
+	if err != nil || (!inner.IsCompound && (len(rest) > 0 || inner.Class != asn1.ClassUniversal)) {
+		return nil
+	}
+
+	lines, err := formatASN1(data, depth)
+	if err != nil {
+		return nil
+	}
+	return lines
+}
+
+func formatASN1Value(v asn1.RawValue) string {
+	if v.Class != asn1.ClassUniversal {
+		if isPrintable(v.Bytes) {
+			return fmt.Sprintf("%q", v.Bytes)
+		}
+		return formatHexValue(v.Bytes)
+	}
+
+	switch v.Tag {
+	case asn1.TagBoolean:
+		if len(v.Bytes) == 1 {
+			return fmt.Sprint(v.Bytes[0] != 0)
+		}
+	case asn1.TagInteger, asn1.TagEnum:
+		if len(v.Bytes) > 16 {
+			return formatHexValue(v.Bytes)
+		}
+		var i *big.Int
+		if _, err := asn1.Unmarshal(v.FullBytes, &i); err == nil {
+			return i.String()
+		}
+		// ENUMERATED cannot be unmarshaled into big.Int
+		return new(big.Int).SetBytes(v.Bytes).String()
+	case asn1.TagBitString:
+		if len(v.Bytes) > 0 {
+			return fmt.Sprintf("(%d bits)", len(v.Bytes[1:])*8-int(v.Bytes[0])) + joinASN1Value(formatHexValue(v.Bytes[1:]))
+		}
+	case asn1.TagNull:
+		return ""
+	case asn1.TagOID:
+		var oid asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(v.FullBytes, &oid); err == nil {
+			if name := OIDName(oid); name != "" {
+				return fmt.Sprintf("%s (%s)", oid, name)
+			}
+			return oid.String()
+		}
+	case asn1.TagUTCTime, asn1.TagGeneralizedTime:
+		var t time.Time
+		if _, err := asn1.Unmarshal(v.FullBytes, &t); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	case asn1.TagBMPString:
+		if len(v.Bytes)%2 == 0 {
+			return fmt.Sprintf("%q", decodeDisplayText(v))
+		}
+	case asn1.TagUTF8String, asn1.TagNumericString, asn1.TagPrintableString, asn1.TagT61String,
+		asn1.TagIA5String, asn1.TagGeneralString, 26:
+		return fmt.Sprintf("%q", v.Bytes)
+	}
+
+	return formatHexValue(v.Bytes)
+}
+
+// joinASN1Value returns a value to be appended after a description.
+func joinASN1Value(value string) string {
+	if strings.HasPrefix(value, "\n") {
+		return value
+	}
+	return " " + value
+}
+
+func isPrintable(b []byte) bool {
+	if len(b) == 0 || !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// formatHexValue returns hex-encoded bytes, starting in a new line and split
+// into lines of 32 bytes when longer than that.
+func formatHexValue(b []byte) string {
+	str := strings.ToUpper(hex.EncodeToString(b))
+	if len(b) <= 32 {
+		return str
+	}
+
+	result := ""
+	for i := 0; i < len(str); i += 64 {
+		result += "\n" + str[i:min(i+64, len(str))]
+	}
+	return result
+}
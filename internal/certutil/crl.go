@@ -0,0 +1,116 @@
+package certutil
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// CRLStatus defines revocation status of a certificate according to a CRL.
+type CRLStatus struct {
+	// URL is the location the CRL was fetched from.
+	URL string
+
+	Revoked        bool
+	RevocationTime time.Time
+	Reason         int
+
+	ThisUpdate time.Time
+	NextUpdate time.Time
+}
+
+// CheckCRL checks with CRLs listed in CRL Distribution Points whether the
+// certificate is revoked at a given time. Distribution points are tried in
+// order until one of them returns a valid CRL. A certificate revoked after
+// that time is not revoked, and a CRL whose next update is before that
+// time can only prove the certificate revoked.
+func CheckCRL(cert *x509.Certificate, issuer *x509.Certificate, at time.Time) (*CRLStatus, error) {
+	if len(cert.CRLDistributionPoints) == 0 {
+		return nil, fmt.Errorf("no CRL distribution point present for certificate")
+	}
+
+	var lastErr error
+	for _, rawURL := range cert.CRLDistributionPoints {
+		crl, err := fetchCRL(rawURL)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if err := crl.CheckSignatureFrom(issuer); err != nil {
+			lastErr = fmt.Errorf("CRL %q: invalid signature: %v", rawURL, err)
+			continue
+		}
+
+		status := &CRLStatus{
+			URL:        rawURL,
+			ThisUpdate: crl.ThisUpdate,
+			NextUpdate: crl.NextUpdate,
+		}
+
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 && !entry.RevocationTime.After(at) {
+				status.Revoked = true
+				status.RevocationTime = entry.RevocationTime
+				status.Reason = entry.ReasonCode
+				break
+			}
+		}
+
+		if !status.Revoked && !crl.NextUpdate.IsZero() && crl.NextUpdate.Before(at) {
+			lastErr = fmt.Errorf("CRL %q is stale: next update was %s", rawURL, crl.NextUpdate.Local())
+			continue
+		}
+
+		return status, nil
+	}
+
+	return nil, lastErr
+}
+
+func fetchCRL(rawURL string) (*x509.RevocationList, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("CRL %q: %v", rawURL, err)
+	}
+
+	var data []byte
+	switch u.Scheme {
+	case "file":
+		if data, err = os.ReadFile(u.Path); err != nil {
+			return nil, fmt.Errorf("CRL %q: %v", rawURL, err)
+		}
+	case "http", "https":
+		resp, err := http.Get(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("CRL %q: %v", rawURL, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("CRL %q: got status code %d", rawURL, resp.StatusCode)
+		}
+
+		buf := bytes.NewBuffer([]byte{})
+		buf.ReadFrom(resp.Body)
+		data = buf.Bytes()
+	default:
+		return nil, fmt.Errorf("CRL %q: unsupported scheme %q", rawURL, u.Scheme)
+	}
+
+	if block, _ := pem.Decode(data); block != nil && block.Type == "X509 CRL" {
+		data = block.Bytes
+	}
+
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, fmt.Errorf("CRL %q: %v", rawURL, err)
+	}
+
+	return crl, nil
+}
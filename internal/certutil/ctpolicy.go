@@ -0,0 +1,162 @@
+package certutil
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	ctlogs "github.com/google/certificate-transparency-go/loglist3"
+)
+
+// shortLivedPeriod defines the certificate lifetime up to which fewer
+// embedded SCTs are required.
+const shortLivedPeriod = 180 * 24 * time.Hour
+
+// CTPolicy defines a Certificate Transparency policy of a user agent.
+type CTPolicy struct {
+	Name string
+
+	// requireCurrentLog requires at least one embedded SCT to come from
+	// a log which is approved at the time of check.
+	requireCurrentLog bool
+}
+
+var (
+	ChromeCTPolicy = CTPolicy{Name: "Chrome", requireCurrentLog: true}
+	AppleCTPolicy  = CTPolicy{Name: "Apple"}
+)
+
+// CTPolicyResult defines result of a CT policy compliance check.
+type CTPolicyResult struct {
+	Policy    string
+	Compliant bool
+	// Unknown reports that compliance cannot be determined because some
+	// SCTs could not be verified.
+	Unknown bool
+	// Reasons lists why the certificate is not compliant.
+	Reasons []string
+}
+
+// Check evaluates the policy against valid SCTs of the certificate at a given time.
+// SCTs issued by logs missing in the log list are ignored.
+func (p CTPolicy) Check(cert *x509.Certificate, scts []SCT, at time.Time) *CTPolicyResult {
+	result := &CTPolicyResult{Policy: p.Name}
+
+	var embedded, delivered []SCT
+	for _, sct := range scts {
+		if sct.Source == SCTEmbedded {
+			embedded = append(embedded, sct)
+		} else {
+			delivered = append(delivered, sct)
+		}
+	}
+
+	if len(embedded) == 0 && len(delivered) == 0 {
+		result.Reasons = []string{"no valid SCTs"}
+		return result
+	}
+
+	if len(embedded) > 0 {
+		reasons := p.checkEmbedded(cert, embedded, at)
+		if len(reasons) == 0 {
+			result.Compliant = true
+			return result
+		}
+		result.Reasons = append(result.Reasons, reasons...)
+	}
+
+	if len(delivered) > 0 {
+		reasons := p.checkDelivered(delivered, at)
+		if len(reasons) == 0 {
+			result.Compliant = true
+			result.Reasons = nil
+			return result
+		}
+		result.Reasons = append(result.Reasons, reasons...)
+	}
+
+	return result
+}
+
+func (p CTPolicy) checkEmbedded(cert *x509.Certificate, scts []SCT, at time.Time) []string {
+	required := 2
+	if cert.NotAfter.Sub(cert.NotBefore) > shortLivedPeriod {
+		required = 3
+	}
+
+	logs, operators, current := map[string]bool{}, map[string]bool{}, false
+	for _, sct := range scts {
+		log := GetSCTLog(sct.SignedCertificateTimestamp)
+		if log == nil || !logAcceptedAt(log.State, sctTime(sct), at) {
+			continue
+		}
+
+		logs[string(log.Key)] = true
+		operators[log.Operator] = true
+		current = current || logApproved(log.State)
+	}
+
+	reasons := []string{}
+	if len(logs) < required {
+		reasons = append(reasons, fmt.Sprintf("embedded: %d SCTs from distinct accepted logs, %d required for this lifetime", len(logs), required))
+	}
+	if len(operators) < 2 {
+		reasons = append(reasons, fmt.Sprintf("embedded: SCTs from %d distinct log operators, 2 required", len(operators)))
+	}
+	if p.requireCurrentLog && !current {
+		reasons = append(reasons, "embedded: no SCT from a currently approved log")
+	}
+	return reasons
+}
+
+func (p CTPolicy) checkDelivered(scts []SCT, at time.Time) []string {
+	logs, operators := map[string]bool{}, map[string]bool{}
+	for _, sct := range scts {
+		log := GetSCTLog(sct.SignedCertificateTimestamp)
+		if log == nil || !logApproved(log.State) {
+			continue
+		}
+
+		logs[string(log.Key)] = true
+		operators[log.Operator] = true
+	}
+
+	reasons := []string{}
+	if len(logs) < 2 {
+		reasons = append(reasons, fmt.Sprintf("TLS/OCSP: %d SCTs from distinct currently approved logs, 2 required", len(logs)))
+	}
+	if len(operators) < 2 {
+		reasons = append(reasons, fmt.Sprintf("TLS/OCSP: SCTs from %d distinct log operators, 2 required", len(operators)))
+	}
+	return reasons
+}
+
+// logApproved reports whether the log is currently qualified, usable or read-only.
+func logApproved(state *ctlogs.LogStates) bool {
+	if state == nil {
+		return false
+	}
+
+	switch state.LogStatus() {
+	case ctlogs.QualifiedLogStatus, ctlogs.UsableLogStatus, ctlogs.ReadOnlyLogStatus:
+		return true
+	}
+	return false
+}
+
+// logAcceptedAt reports whether SCTs issued by the log at a given time are
+// accepted. SCTs from retired logs are accepted if issued before retirement.
+func logAcceptedAt(state *ctlogs.LogStates, issued, at time.Time) bool {
+	if logApproved(state) {
+		return true
+	}
+
+	if state != nil && state.Retired != nil {
+		return issued.Before(state.Retired.Timestamp) && issued.Before(at)
+	}
+	return false
+}
+
+func sctTime(sct SCT) time.Time {
+	return time.UnixMilli(int64(sct.Timestamp))
+}
@@ -0,0 +1,227 @@
+package certutil
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DN defines a distinguished name as a sequence of relative distinguished
+// names, in the order they are encoded. Unlike pkix.Name it keeps
+// multi-valued RDNs and values of any type.
+type DN [][]DNAttribute
+
+// DNAttribute defines an attribute type and value of a distinguished name.
+type DNAttribute struct {
+	Type  asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+// ParseDN parses a DER-encoded distinguished name.
+func ParseDN(der []byte) (DN, error) {
+	var raw []asn1.RawValue
+	if rest, err := asn1.Unmarshal(der, &raw); err != nil {
+		return nil, fmt.Errorf("cannot parse distinguished name: %w", err)
+	} else if len(rest) > 0 {
+		return nil, fmt.Errorf("trailing data after distinguished name")
+	}
+
+	dn := DN{}
+	for _, set := range raw {
+		var rdn []DNAttribute
+		if _, err := asn1.UnmarshalWithParams(set.FullBytes, &rdn, "set"); err != nil {
+			return nil, fmt.Errorf("cannot parse relative distinguished name: %w", err)
+		}
+		dn = append(dn, rdn)
+	}
+	return dn, nil
+}
+
+// StringValue returns the value as text, or false if it has no string syntax.
+func (a DNAttribute) StringValue() (string, bool) {
+	v := a.Value
+	if v.Class != asn1.ClassUniversal {
+		return "", false
+	}
+
+	switch v.Tag {
+	case asn1.TagUTF8String, asn1.TagPrintableString, asn1.TagIA5String, asn1.TagNumericString, asn1.TagT61String, 26: // VisibleString
+		return string(v.Bytes), true
+	case asn1.TagBMPString:
+		return decodeDisplayText(v), true
+	case 28: // UniversalString
+		if len(v.Bytes)%4 != 0 {
+			return "", false
+		}
+		b := strings.Builder{}
+		for i := 0; i < len(v.Bytes); i += 4 {
+			r := rune(v.Bytes[i])<<24 | rune(v.Bytes[i+1])<<16 | rune(v.Bytes[i+2])<<8 | rune(v.Bytes[i+3])
+			b.WriteRune(r)
+		}
+		return b.String(), true
+	}
+	return "", false
+}
+
+// Text returns the value as text, or as '#' followed by hex-encoded DER
+// when it has no string syntax (RFC 4514).
+func (a DNAttribute) Text() string {
+	if s, ok := a.StringValue(); ok {
+		return s
+	}
+	return "#" + hex.EncodeToString(a.Value.FullBytes)
+}
+
+// rfc4514Names defines attribute type names used in RFC 4514 strings: the
+// short names of RFC 4514, section 3, and other descriptors of RFC 4519.
+var rfc4514Names = map[string]string{
+	"2.5.4.3":                    "CN",
+	"2.5.4.4":                    "sn",
+	"2.5.4.5":                    "serialNumber",
+	"2.5.4.6":                    "C",
+	"2.5.4.7":                    "L",
+	"2.5.4.8":                    "ST",
+	"2.5.4.9":                    "street",
+	"2.5.4.10":                   "O",
+	"2.5.4.11":                   "OU",
+	"2.5.4.12":                   "title",
+	"2.5.4.13":                   "description",
+	"2.5.4.15":                   "businessCategory",
+	"2.5.4.16":                   "postalAddress",
+	"2.5.4.17":                   "postalCode",
+	"2.5.4.18":                   "postOfficeBox",
+	"2.5.4.20":                   "telephoneNumber",
+	"2.5.4.41":                   "name",
+	"2.5.4.42":                   "givenName",
+	"2.5.4.43":                   "initials",
+	"2.5.4.44":                   "generationQualifier",
+	"2.5.4.45":                   "x500UniqueIdentifier",
+	"2.5.4.46":                   "dnQualifier",
+	"0.9.2342.19200300.100.1.1":  "UID",
+	"0.9.2342.19200300.100.1.25": "DC",
+}
+
+// openSSLNames defines attribute type names known to OpenSSL (short names,
+// or long names when there is no short one).
+var openSSLNames = map[string]string{
+	"2.5.4.3":                    "CN",
+	"2.5.4.4":                    "SN",
+	"2.5.4.5":                    "serialNumber",
+	"2.5.4.6":                    "C",
+	"2.5.4.7":                    "L",
+	"2.5.4.8":                    "ST",
+	"2.5.4.9":                    "street",
+	"2.5.4.10":                   "O",
+	"2.5.4.11":                   "OU",
+	"2.5.4.12":                   "title",
+	"2.5.4.13":                   "description",
+	"2.5.4.15":                   "businessCategory",
+	"2.5.4.16":                   "postalAddress",
+	"2.5.4.17":                   "postalCode",
+	"2.5.4.18":                   "postOfficeBox",
+	"2.5.4.20":                   "telephoneNumber",
+	"2.5.4.41":                   "name",
+	"2.5.4.42":                   "GN",
+	"2.5.4.43":                   "initials",
+	"2.5.4.44":                   "generationQualifier",
+	"2.5.4.45":                   "x500UniqueIdentifier",
+	"2.5.4.46":                   "dnQualifier",
+	"2.5.4.54":                   "dmdName",
+	"2.5.4.65":                   "pseudonym",
+	"2.5.4.72":                   "role",
+	"2.5.4.97":                   "organizationIdentifier",
+	"1.2.840.113549.1.9.1":       "emailAddress",
+	"0.9.2342.19200300.100.1.1":  "UID",
+	"0.9.2342.19200300.100.1.25": "DC",
+	"1.3.6.1.4.1.311.60.2.1.1":   "jurisdictionL",
+	"1.3.6.1.4.1.311.60.2.1.2":   "jurisdictionST",
+	"1.3.6.1.4.1.311.60.2.1.3":   "jurisdictionC",
+}
+
+// typeName returns the attribute type name from a given table, or the
+// dotted OID when it has no name there.
+func (a DNAttribute) typeName(names map[string]string) string {
+	if name, ok := names[a.Type.String()]; ok {
+		return name
+	}
+	return a.Type.String()
+}
+
+// RFC4514 returns the distinguished name as a string (RFC 4514), as used
+// in LDAP, with the last RDN first. Values of types without a short name
+// are written as '#' followed by hex-encoded DER (RFC 4514, section 2.4).
+func (dn DN) RFC4514() string {
+	rdns := make([]string, len(dn))
+	for i, rdn := range dn {
+		attrs := make([]string, len(rdn))
+		for j, a := range rdn {
+			name, ok := rfc4514Names[a.Type.String()]
+			if !ok {
+				name = a.Type.String()
+			}
+			value := "#" + hex.EncodeToString(a.Value.FullBytes)
+			if s, isString := a.StringValue(); ok && isString {
+				value = escapeRFC4514(s)
+			}
+			attrs[j] = name + "=" + value
+		}
+		rdns[len(dn)-1-i] = strings.Join(attrs, "+")
+	}
+	return strings.Join(rdns, ",")
+}
+
+func escapeRFC4514(s string) string {
+	b := strings.Builder{}
+	for i, r := range s {
+		switch {
+		case r == 0:
+			b.WriteString(`\00`)
+			continue
+		case strings.ContainsRune(`"+,;<>\`, r),
+			i == 0 && (r == ' ' || r == '#'),
+			i == len(s)-utf8.RuneLen(r) && r == ' ':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// OpenSSL returns the distinguished name in the form accepted by the -subj
+// option of OpenSSL commands, with the first RDN first.
+func (dn DN) OpenSSL() string {
+	b := strings.Builder{}
+	for _, rdn := range dn {
+		b.WriteByte('/')
+		for j, a := range rdn {
+			if j > 0 {
+				b.WriteByte('+')
+			}
+			b.WriteString(a.typeName(openSSLNames))
+			b.WriteByte('=')
+			for _, r := range a.Text() {
+				if r == '/' || r == '+' || r == '\\' {
+					b.WriteByte('\\')
+				}
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Multiline returns the distinguished name with one RDN per line, in the
+// order they are encoded. Values of multi-valued RDNs are joined with " + ".
+func (dn DN) Multiline() string {
+	lines := make([]string, len(dn))
+	for i, rdn := range dn {
+		attrs := make([]string, len(rdn))
+		for j, a := range rdn {
+			attrs[j] = OIDNameOrString(a.Type) + "=" + a.Text()
+		}
+		lines[i] = strings.Join(attrs, " + ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,38 @@
+package certutil_test
+
+import (
+	"encoding/asn1"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+func TestRFC4514_RegisteredNames(t *testing.T) {
+	type atv struct {
+		Type  asn1.ObjectIdentifier
+		Value asn1.RawValue
+	}
+	// slice types named *SET are marshalled as SET OF
+	type rdnSET []atv
+
+	der, err := asn1.Marshal([]rdnSET{
+		{{asn1.ObjectIdentifier{2, 5, 4, 10}, asn1.RawValue{Tag: asn1.TagUTF8String, Bytes: []byte("Acme")}}},
+		{{asn1.ObjectIdentifier{1, 2, 3}, asn1.RawValue{Tag: asn1.TagInteger, Bytes: []byte{7}}}},
+	})
+	if err != nil {
+		t.Fatalf("cannot marshal: %s", err)
+	}
+	dn, err := certutil.ParseDN(der)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	t.Cleanup(certutil.ResetOIDs)
+	certutil.RegisterOID(asn1.ObjectIdentifier{1, 2, 3}, "Example Attribute")
+	certutil.RegisterOID(asn1.ObjectIdentifier{2, 5, 4, 10}, "organization")
+
+	if diff := cmp.Diff("1.2.3=#020107,O=Acme", dn.RFC4514()); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
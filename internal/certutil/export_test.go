@@ -0,0 +1,17 @@
+package certutil
+
+import ctlogs "github.com/google/certificate-transparency-go/loglist3"
+
+// Hooks restoring global state changed by tests.
+var (
+	ResetOIDs            = resetOIDs
+	ResetDebianBlocklist = resetDebianBlocklist
+)
+
+// SetLogList replaces the CT log list and returns a function restoring
+// the previous one.
+func SetLogList(ll *ctlogs.LogList) func() {
+	prev := ctLogList
+	ctLogList = ll
+	return func() { ctLogList = prev }
+}
@@ -0,0 +1,213 @@
+package certutil
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Standard certificate extensions (RFC 5280, section 4.2).
+var (
+	OIDSubjectDirectoryAttributesExt = asn1.ObjectIdentifier{2, 5, 29, 9}
+	OIDSubjectKeyIDExt               = asn1.ObjectIdentifier{2, 5, 29, 14}
+	OIDKeyUsageExt                   = asn1.ObjectIdentifier{2, 5, 29, 15}
+	OIDSubjectAltNameExt             = asn1.ObjectIdentifier{2, 5, 29, 17}
+	OIDIssuerAltNameExt              = asn1.ObjectIdentifier{2, 5, 29, 18}
+	OIDBasicConstraintsExt           = asn1.ObjectIdentifier{2, 5, 29, 19}
+	OIDNameConstraintsExt            = asn1.ObjectIdentifier{2, 5, 29, 30}
+	OIDCRLDistributionPointsExt      = asn1.ObjectIdentifier{2, 5, 29, 31}
+	OIDCertificatePoliciesExt        = asn1.ObjectIdentifier{2, 5, 29, 32}
+	OIDPolicyMappingsExt             = asn1.ObjectIdentifier{2, 5, 29, 33}
+	OIDAuthorityKeyIDExt             = asn1.ObjectIdentifier{2, 5, 29, 35}
+	OIDPolicyConstraintsExt          = asn1.ObjectIdentifier{2, 5, 29, 36}
+	OIDExtKeyUsageExt                = asn1.ObjectIdentifier{2, 5, 29, 37}
+	OIDFreshestCRLExt                = asn1.ObjectIdentifier{2, 5, 29, 46}
+	OIDInhibitAnyPolicyExt           = asn1.ObjectIdentifier{2, 5, 29, 54}
+	OIDAuthorityInfoAccessExt        = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 1}
+	OIDSubjectInfoAccessExt          = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 11}
+)
+
+// IsCriticalExtension reports whether the certificate contains the extension marked as critical.
+func IsCriticalExtension(cert *x509.Certificate, oid asn1.ObjectIdentifier) bool {
+	for _, e := range cert.Extensions {
+		if e.Id.Equal(oid) {
+			return e.Critical
+		}
+	}
+	return false
+}
+
+type nameConstraints struct {
+	Permitted []generalSubtree `asn1:"optional,tag:0"`
+	Excluded  []generalSubtree `asn1:"optional,tag:1"`
+}
+
+type generalSubtree struct {
+	Base    asn1.RawValue
+	Minimum int `asn1:"optional,tag:0"`
+	Maximum int `asn1:"optional,tag:1"`
+}
+
+// GetNameConstraints returns permitted and excluded subtrees of the Name
+// Constraints extension with bases crypto/x509 does not decode: all types
+// except DNS names, email addresses, IP ranges and URIs.
+func GetNameConstraints(cert *x509.Certificate) (permitted, excluded *AltNames, err error) {
+	permitted, excluded = &AltNames{}, &AltNames{}
+	for _, e := range cert.Extensions {
+		if !e.Id.Equal(OIDNameConstraintsExt) {
+			continue
+		}
+
+		var nc nameConstraints
+		if rest, err := asn1.Unmarshal(e.Value, &nc); err != nil || len(rest) > 0 {
+			return nil, nil, fmt.Errorf("cannot parse name constraints")
+		}
+
+		for _, s := range []struct {
+			subtrees []generalSubtree
+			names    *AltNames
+		}{{nc.Permitted, permitted}, {nc.Excluded, excluded}} {
+			for _, subtree := range s.subtrees {
+				switch subtree.Base.Tag {
+				case nameTypeDNS, nameTypeEmail, nameTypeIP, nameTypeURI:
+					continue
+				}
+				if err := s.names.add(subtree.Base); err != nil {
+					return nil, nil, fmt.Errorf("cannot parse name constraints: %w", err)
+				}
+			}
+		}
+	}
+	return permitted, excluded, nil
+}
+
+// DirectoryAttribute defines an attribute of the Subject Directory Attributes extension.
+type DirectoryAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []string
+}
+
+// Name returns name of the attribute type, or its OID when unknown.
+func (a DirectoryAttribute) Name() string {
+	return OIDNameOrString(a.Type)
+}
+
+type directoryAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// GetSubjectDirectoryAttributes returns attributes of the Subject Directory Attributes extension.
+func GetSubjectDirectoryAttributes(cert *x509.Certificate) ([]DirectoryAttribute, error) {
+	for _, e := range cert.Extensions {
+		if !e.Id.Equal(OIDSubjectDirectoryAttributesExt) {
+			continue
+		}
+
+		var raw []directoryAttribute
+		if _, err := asn1.Unmarshal(e.Value, &raw); err != nil {
+			return nil, fmt.Errorf("cannot parse subject directory attributes: %w", err)
+		}
+
+		attrs := make([]DirectoryAttribute, len(raw))
+		for i, a := range raw {
+			attrs[i].Type = a.Type
+			for _, v := range a.Values {
+				attrs[i].Values = append(attrs[i].Values, formatAttributeValue(v))
+			}
+		}
+		return attrs, nil
+	}
+	return nil, nil
+}
+
+// formatAttributeValue returns strings and times as text, and other values hex-encoded.
+func formatAttributeValue(v asn1.RawValue) string {
+	if v.Class == asn1.ClassUniversal {
+		switch v.Tag {
+		case asn1.TagUTF8String, asn1.TagPrintableString, asn1.TagIA5String, asn1.TagT61String, asn1.TagNumericString:
+			return string(v.Bytes)
+		case asn1.TagGeneralizedTime, asn1.TagUTCTime:
+			var t time.Time
+			if _, err := asn1.Unmarshal(v.FullBytes, &t); err == nil {
+				return t.UTC().Format(time.DateOnly)
+			}
+		}
+	}
+	return hex.EncodeToString(v.FullBytes)
+}
+
+type distributionPoint struct {
+	DistributionPoint distributionPointName `asn1:"optional,tag:0"`
+	Reason            asn1.BitString        `asn1:"optional,tag:1"`
+	CRLIssuer         asn1.RawValue         `asn1:"optional,tag:2"`
+}
+
+type distributionPointName struct {
+	FullName     []asn1.RawValue  `asn1:"optional,tag:0"`
+	RelativeName pkix.RDNSequence `asn1:"optional,tag:1"`
+}
+
+// GetFreshestCRL returns full names of delta CRL distribution points of the
+// Freshest CRL extension.
+func GetFreshestCRL(cert *x509.Certificate) (*AltNames, error) {
+	names := &AltNames{}
+	for _, e := range cert.Extensions {
+		if !e.Id.Equal(OIDFreshestCRLExt) {
+			continue
+		}
+
+		var points []distributionPoint
+		if rest, err := asn1.Unmarshal(e.Value, &points); err != nil || len(rest) > 0 {
+			return nil, fmt.Errorf("cannot parse freshest CRL")
+		}
+
+		for _, p := range points {
+			for _, v := range p.DistributionPoint.FullName {
+				if err := names.add(v); err != nil {
+					return nil, fmt.Errorf("cannot parse freshest CRL: %w", err)
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// AccessDescription defines an access method and location of the Subject
+// Information Access extension.
+type AccessDescription struct {
+	Method   asn1.ObjectIdentifier
+	Location *AltNames
+}
+
+type accessDescription struct {
+	Method   asn1.ObjectIdentifier
+	Location asn1.RawValue
+}
+
+// GetSubjectInfoAccess returns access descriptions of the Subject Information Access extension.
+func GetSubjectInfoAccess(cert *x509.Certificate) ([]AccessDescription, error) {
+	for _, e := range cert.Extensions {
+		if !e.Id.Equal(OIDSubjectInfoAccessExt) {
+			continue
+		}
+
+		var raw []accessDescription
+		if rest, err := asn1.Unmarshal(e.Value, &raw); err != nil || len(rest) > 0 {
+			return nil, fmt.Errorf("cannot parse subject information access")
+		}
+
+		result := make([]AccessDescription, len(raw))
+		for i, ad := range raw {
+			result[i] = AccessDescription{Method: ad.Method, Location: &AltNames{}}
+			if err := result[i].Location.add(ad.Location); err != nil {
+				return nil, fmt.Errorf("cannot parse subject information access: %w", err)
+			}
+		}
+		return result, nil
+	}
+	return nil, nil
+}
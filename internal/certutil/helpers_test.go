@@ -0,0 +1,400 @@
+package certutil_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ct "github.com/google/certificate-transparency-go"
+	"github.com/google/certificate-transparency-go/loglist3"
+	cttls "github.com/google/certificate-transparency-go/tls"
+	ctx509 "github.com/google/certificate-transparency-go/x509"
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+var equateErrorMessage = cmp.Comparer(func(x, y error) bool {
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	return x.Error() == y.Error()
+})
+
+type testChain struct {
+	root, intermediate, leaf          *x509.Certificate
+	rootKey, intermediateKey, leafKey *ecdsa.PrivateKey
+
+	leafTemplate *x509.Certificate
+}
+
+// newTestChain generates a root, an intermediate and a leaf certificate.
+// The leaf template can be adjusted with modify before signing.
+func newTestChain(t *testing.T, modify func(leaf *x509.Certificate)) *testChain {
+	t.Helper()
+
+	now := time.Now()
+	tc := &testChain{}
+
+	tc.rootKey = newTestKey(t)
+	tc.root = signTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, &tc.rootKey.PublicKey, tc.rootKey)
+
+	tc.intermediateKey = newTestKey(t)
+	tc.intermediate = signTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Test Intermediate"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(60 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, tc.root, &tc.intermediateKey.PublicKey, tc.rootKey)
+
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(90 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if modify != nil {
+		modify(leaf)
+	}
+
+	tc.leafKey = newTestKey(t)
+	tc.leafTemplate = leaf
+	tc.leaf = signTestCert(t, leaf, tc.intermediate, &tc.leafKey.PublicKey, tc.intermediateKey)
+
+	return tc
+}
+
+func (tc *testChain) certificate() *internal.Certificate {
+	cert := internal.NewCertificate(tc.leaf)
+	cert.AddCertificateToChain(internal.NewCertificate(tc.intermediate))
+	cert.AddCertificateToChain(internal.NewCertificate(tc.root))
+	return cert
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate key: %s", err)
+	}
+	return key
+}
+
+func signTestCert(t *testing.T, template, parent *x509.Certificate, pub any, priv crypto.Signer) *x509.Certificate {
+	t.Helper()
+
+	if parent == nil {
+		parent = template
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+	if err != nil {
+		t.Fatalf("cannot create certificate: %s", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("cannot parse certificate: %s", err)
+	}
+	return cert
+}
+
+// startTLSServer starts a TLS server presenting the test chain and returns
+// its address. The server only completes handshakes.
+func startTLSServer(t *testing.T, tc *testChain, configure func(*tls.Certificate)) string {
+	t.Helper()
+
+	tlsCert := tls.Certificate{
+		Certificate: [][]byte{tc.leaf.Raw, tc.intermediate.Raw},
+		PrivateKey:  tc.leafKey,
+	}
+	if configure != nil {
+		configure(&tlsCert)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{tlsCert}})
+	if err != nil {
+		t.Fatalf("cannot start TLS server: %s", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+type testLog struct {
+	description string
+	operator    string
+	key         *ecdsa.PrivateKey
+	rawKey      []byte
+	id          [sha256.Size]byte
+
+	// state defaults to usable when nil
+	state *loglist3.LogStates
+}
+
+func newTestLog(t *testing.T, description, operator string) *testLog {
+	t.Helper()
+
+	key := newTestKey(t)
+	rawKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("cannot marshal log key: %s", err)
+	}
+
+	return &testLog{description: description, operator: operator, key: key, rawKey: rawKey, id: sha256.Sum256(rawKey)}
+}
+
+// loadTestLogList replaces the CT log list with one containing given logs
+// until the test ends.
+func loadTestLogList(t *testing.T, logs ...*testLog) {
+	t.Helper()
+
+	operators := map[string]*loglist3.Operator{}
+	ll := &loglist3.LogList{}
+	for _, l := range logs {
+		op, ok := operators[l.operator]
+		if !ok {
+			op = &loglist3.Operator{Name: l.operator}
+			operators[l.operator] = op
+			ll.Operators = append(ll.Operators, op)
+		}
+		state := l.state
+		if state == nil {
+			state = &loglist3.LogStates{Usable: &loglist3.LogState{Timestamp: time.Now().Add(-365 * 24 * time.Hour)}}
+		}
+		op.Logs = append(op.Logs, &loglist3.Log{
+			Description: l.description,
+			LogID:       l.id[:],
+			Key:         l.rawKey,
+			URL:         "https://" + l.description + "/",
+			State:       state,
+		})
+	}
+
+	t.Cleanup(certutil.SetLogList(ll))
+}
+
+// sign issues a SCT for the entry.
+func (l *testLog) sign(t *testing.T, entry *ct.TimestampedEntry, timestamp time.Time) ct.SignedCertificateTimestamp {
+	t.Helper()
+
+	sct := ct.SignedCertificateTimestamp{
+		SCTVersion: ct.V1,
+		LogID:      ct.LogID{KeyID: l.id},
+		Timestamp:  uint64(timestamp.UnixMilli()),
+	}
+
+	data, err := ct.SerializeSCTSignatureInput(sct, ct.LogEntry{Leaf: ct.MerkleTreeLeaf{TimestampedEntry: entry}})
+	if err != nil {
+		t.Fatalf("cannot serialize SCT: %s", err)
+	}
+
+	signature, err := cttls.CreateSignature(*l.key, cttls.SHA256, data)
+	if err != nil {
+		t.Fatalf("cannot sign SCT: %s", err)
+	}
+	sct.Signature = ct.DigitallySigned(signature)
+
+	return sct
+}
+
+// marshalSCTList encodes SCTs as a SignedCertificateTimestampList.
+func marshalSCTList(t *testing.T, scts ...ct.SignedCertificateTimestamp) []byte {
+	t.Helper()
+
+	list := ctx509.SignedCertificateTimestampList{}
+	for _, sct := range scts {
+		val, err := cttls.Marshal(sct)
+		if err != nil {
+			t.Fatalf("cannot marshal SCT: %s", err)
+		}
+		list.SCTList = append(list.SCTList, ctx509.SerializedSCT{Val: val})
+	}
+
+	data, err := cttls.Marshal(list)
+	if err != nil {
+		t.Fatalf("cannot marshal SCT list: %s", err)
+	}
+	return data
+}
+
+// embedSCTs reissues the leaf certificate with SCTs from given logs embedded.
+func (tc *testChain) embedSCTs(t *testing.T, logs ...*testLog) {
+	t.Helper()
+
+	template := *tc.leafTemplate
+	precert := signTestCert(t, &template, tc.intermediate, &tc.leafKey.PublicKey, tc.intermediateKey)
+
+	entry := &ct.TimestampedEntry{
+		EntryType: ct.PrecertLogEntryType,
+		PrecertEntry: &ct.PreCert{
+			IssuerKeyHash:  sha256.Sum256(tc.intermediate.RawSubjectPublicKeyInfo),
+			TBSCertificate: precert.RawTBSCertificate,
+		},
+	}
+
+	scts := []ct.SignedCertificateTimestamp{}
+	for _, l := range logs {
+		scts = append(scts, l.sign(t, entry, time.Now()))
+	}
+
+	value, err := asn1.Marshal(marshalSCTList(t, scts...))
+	if err != nil {
+		t.Fatalf("cannot marshal SCT extension: %s", err)
+	}
+
+	extensions := append([]pkix.Extension{}, template.ExtraExtensions...)
+	template.ExtraExtensions = append(extensions, pkix.Extension{
+		Id:    asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2},
+		Value: value,
+	})
+	tc.leaf = signTestCert(t, &template, tc.intermediate, &tc.leafKey.PublicKey, tc.intermediateKey)
+}
+
+// serve starts a RFC 6962 log server whose Merkle tree consists of given
+// leaf hashes and returns its URL.
+func (l *testLog) serve(t *testing.T, leafHashes [][]byte) string {
+	t.Helper()
+
+	sth := ct.SignedTreeHead{
+		Version:   ct.V1,
+		TreeSize:  uint64(len(leafHashes)),
+		Timestamp: uint64(time.Now().Add(time.Minute).UnixMilli()),
+	}
+	copy(sth.SHA256RootHash[:], merkleTreeHash(leafHashes))
+
+	data, err := ct.SerializeSTHSignatureInput(sth)
+	if err != nil {
+		t.Fatalf("cannot serialize STH: %s", err)
+	}
+	signature, err := cttls.CreateSignature(*l.key, cttls.SHA256, data)
+	if err != nil {
+		t.Fatalf("cannot sign STH: %s", err)
+	}
+	rawSignature, err := cttls.Marshal(signature)
+	if err != nil {
+		t.Fatalf("cannot marshal STH signature: %s", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ct/v1/get-sth", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ct.GetSTHResponse{
+			TreeSize:          sth.TreeSize,
+			Timestamp:         sth.Timestamp,
+			SHA256RootHash:    sth.SHA256RootHash[:],
+			TreeHeadSignature: rawSignature,
+		})
+	})
+	mux.HandleFunc("/ct/v1/get-proof-by-hash", func(w http.ResponseWriter, r *http.Request) {
+		hash, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("hash"))
+		for i, h := range leafHashes {
+			if bytes.Equal(h, hash) {
+				json.NewEncoder(w).Encode(ct.GetProofByHashResponse{
+					LeafIndex: int64(i),
+					AuditPath: merkleAuditPath(i, leafHashes),
+				})
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv.URL
+}
+
+// merkleTreeHash computes the Merkle Tree Hash (RFC 6962, section 2.1).
+func merkleTreeHash(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+
+	k := merkleSplit(len(leaves))
+	return rfc6962.DefaultHasher.HashChildren(merkleTreeHash(leaves[:k]), merkleTreeHash(leaves[k:]))
+}
+
+// merkleAuditPath computes the Merkle audit path (RFC 6962, section 2.1.1).
+func merkleAuditPath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) == 1 {
+		return nil
+	}
+
+	k := merkleSplit(len(leaves))
+	if m < k {
+		return append(merkleAuditPath(m, leaves[:k]), merkleTreeHash(leaves[k:]))
+	}
+	return append(merkleAuditPath(m-k, leaves[k:]), merkleTreeHash(leaves[:k]))
+}
+
+// merkleSplit returns the largest power of two smaller than n.
+func merkleSplit(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// precertificate issues a precertificate for the leaf template.
+func (tc *testChain) precertificate(t *testing.T) *x509.Certificate {
+	t.Helper()
+
+	template := *tc.leafTemplate
+	extensions := append([]pkix.Extension{}, template.ExtraExtensions...)
+	template.ExtraExtensions = append(extensions, pkix.Extension{Id: certutil.OIDCTPoisonExt, Critical: true, Value: []byte{0x05, 0x00}})
+
+	return signTestCert(t, &template, tc.intermediate, &tc.leafKey.PublicKey, tc.intermediateKey)
+}
+
+func mustOID(t *testing.T, s string) x509.OID {
+	t.Helper()
+
+	oid, err := x509.ParseOID(s)
+	if err != nil {
+		t.Fatalf("cannot parse OID: %s", err)
+	}
+	return oid
+}
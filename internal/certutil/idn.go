@@ -0,0 +1,37 @@
+package certutil
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// ToUnicode converts A-labels ("xn--") of an internationalized domain name
+// to Unicode, validating them with IDNA lookup rules (UTS #46). Other labels,
+// such as the wildcard, are returned unchanged.
+func ToUnicode(name string) (string, error) {
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if !strings.HasPrefix(strings.ToLower(label), "xn--") {
+			continue
+		}
+
+		decoded, err := idna.Lookup.ToUnicode(label)
+		if err != nil {
+			return "", fmt.Errorf("invalid IDN label %q: %w", label, err)
+		}
+		labels[i] = decoded
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// IsIDN reports whether the domain name contains A-labels.
+func IsIDN(name string) bool {
+	for _, label := range strings.Split(name, ".") {
+		if strings.HasPrefix(strings.ToLower(label), "xn--") {
+			return true
+		}
+	}
+	return false
+}
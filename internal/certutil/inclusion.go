@@ -0,0 +1,125 @@
+package certutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"time"
+
+	ct "github.com/google/certificate-transparency-go"
+	ctclient "github.com/google/certificate-transparency-go/client"
+	"github.com/google/certificate-transparency-go/ctutil"
+	"github.com/google/certificate-transparency-go/jsonclient"
+	"github.com/transparency-dev/merkle/proof"
+	"github.com/transparency-dev/merkle/rfc6962"
+)
+
+// InclusionProof defines a verified proof that a certificate is included
+// in the Merkle tree of a CT log.
+type InclusionProof struct {
+	LogURL    string
+	LeafIndex int64
+	TreeSize  uint64
+	// TreeHeadTime is the timestamp of the Signed Tree Head the proof was verified against.
+	TreeHeadTime time.Time
+}
+
+// ParseLogID parses a base64-encoded CT log ID.
+func ParseLogID(s string) (ct.SHA256Hash, error) {
+	var id ct.SHA256Hash
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(raw) != sha256.Size {
+		return id, fmt.Errorf("log ID %q: invalid SHA-256 hash", s)
+	}
+	copy(id[:], raw)
+	return id, nil
+}
+
+// ParseLogKey parses a PEM or base64-encoded public key (SubjectPublicKeyInfo)
+// of a CT log. It returns the DER-encoded key and the log ID derived from it.
+func ParseLogKey(s string) ([]byte, ct.SHA256Hash, error) {
+	var der []byte
+	if block, _ := pem.Decode([]byte(s)); block != nil {
+		der = block.Bytes
+	} else if raw, err := base64.StdEncoding.DecodeString(s); err == nil {
+		der = raw
+	}
+
+	if _, err := x509.ParsePKIXPublicKey(der); err != nil {
+		return nil, ct.SHA256Hash{}, fmt.Errorf("log key: invalid public key")
+	}
+	return der, sha256.Sum256(der), nil
+}
+
+// VerifyInclusion fetches the Signed Tree Head and the audit path from the
+// log which issued the SCT and verifies the certificate is included in its
+// Merkle tree. If logURL is empty, the URL from the log list is used. If
+// logKey (DER-encoded) is set, it is used to verify the Signed Tree Head
+// instead of the key from the log list, so logs absent from the list can
+// be checked.
+func VerifyInclusion(ctx context.Context, sct ct.SignedCertificateTimestamp, cert *x509.Certificate, issuer *x509.Certificate, embedded bool, logURL string, logKey []byte) (*InclusionProof, error) {
+	log := GetSCTLog(sct)
+
+	if len(logKey) > 0 {
+		if sha256.Sum256(logKey) != sct.LogID.KeyID {
+			return nil, fmt.Errorf("log key does not match the log ID of the SCT")
+		}
+	} else if log != nil {
+		logKey = log.Key
+	}
+
+	if logURL == "" && log != nil {
+		if log.Tiled {
+			return nil, fmt.Errorf("log %q does not support RFC 6962 API", log.Description)
+		}
+		logURL = log.URL
+	}
+
+	if logURL == "" || len(logKey) == 0 {
+		return nil, fmt.Errorf("log %s is not in the CT log list: its URL and public key are required", base64.StdEncoding.EncodeToString(sct.LogID.KeyID[:]))
+	}
+
+	chain, err := toCTChain(cert, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	leafHash, err := ctutil.LeafHash(chain, &sct, embedded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute leaf hash: %v", err)
+	}
+
+	client, err := ctclient.New(logURL, http.DefaultClient, jsonclient.Options{PublicKeyDER: logKey})
+	if err != nil {
+		return nil, err
+	}
+
+	sth, err := client.GetSTH(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get STH: %v", err)
+	}
+
+	if sth.Timestamp < sct.Timestamp {
+		return nil, fmt.Errorf("SCT is newer than the latest STH")
+	}
+
+	resp, err := client.GetProofByHash(ctx, leafHash[:], sth.TreeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inclusion proof: %v", err)
+	}
+
+	if err := proof.VerifyInclusion(rfc6962.DefaultHasher, uint64(resp.LeafIndex), sth.TreeSize, leafHash[:], resp.AuditPath, sth.SHA256RootHash[:]); err != nil {
+		return nil, fmt.Errorf("invalid inclusion proof: %v", err)
+	}
+
+	return &InclusionProof{
+		LogURL:       logURL,
+		LeafIndex:    resp.LeafIndex,
+		TreeSize:     sth.TreeSize,
+		TreeHeadTime: time.UnixMilli(int64(sth.Timestamp)),
+	}, nil
+}
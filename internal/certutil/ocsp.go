@@ -3,35 +3,186 @@ package certutil
 import (
 	"bytes"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/ocsp"
 )
 
-// CheckOCSP checks with OCSP server whether the certificate is revoked.
-func CheckOCSP(cert *x509.Certificate, issuer *x509.Certificate) (bool, error) {
+// maxOCSPGetLength defines maximum length of an encoded request sent using
+// GET method (RFC 6960, appendix A.1). Longer requests are sent using POST.
+const maxOCSPGetLength = 255
+
+// OCSPStatus defines revocation status of a certificate according to
+// an OCSP response.
+type OCSPStatus struct {
+	// URL is the location of the responder. It is empty for stapled responses.
+	URL string
+
+	Status         int
+	RevocationTime time.Time
+	Reason         int
+
+	ProducedAt time.Time
+	ThisUpdate time.Time
+	NextUpdate time.Time
+
+	// ResponderName and ResponderKeyHash identify the responder;
+	// only one of them is set.
+	ResponderName    string
+	ResponderKeyHash []byte
+
+	// Delegated reports whether the response was signed by a delegated
+	// responder instead of the issuer itself.
+	Delegated bool
+}
+
+// Good reports whether the responder confirmed the certificate is not revoked.
+func (s *OCSPStatus) Good() bool {
+	return s.Status == ocsp.Good
+}
+
+// Revoked reports whether the responder reported the certificate as revoked.
+func (s *OCSPStatus) Revoked() bool {
+	return s.Status == ocsp.Revoked
+}
+
+// StatusString returns a name of the certificate status.
+func (s *OCSPStatus) StatusString() string {
+	switch s.Status {
+	case ocsp.Good:
+		return "good"
+	case ocsp.Revoked:
+		return "revoked"
+	default:
+		return "unknown"
+	}
+}
+
+// CheckOCSP checks with all OCSP servers of the certificate whether it is
+// revoked at a given time. It returns valid responses of all responders
+// along with errors of the ones which failed.
+func CheckOCSP(cert *x509.Certificate, issuer *x509.Certificate, at time.Time) ([]*OCSPStatus, error) {
 	if len(cert.OCSPServer) == 0 {
-		return false, fmt.Errorf("no OCSP server present for certificate")
+		return nil, fmt.Errorf("no OCSP server present for certificate")
 	}
 
 	body, err := ocsp.CreateRequest(cert, issuer, nil)
 	if err != nil {
-		return false, fmt.Errorf("OCSP request error")
+		return nil, fmt.Errorf("OCSP request error: %v", err)
 	}
 
-	resp, err := http.Post(cert.OCSPServer[0], "application/ocsp-request", bytes.NewReader(body))
-	if err != nil || resp.StatusCode != 200 {
-		return false, fmt.Errorf("OCSP request error")
+	result := []*OCSPStatus{}
+	var errs []error
+	for _, server := range cert.OCSPServer {
+		data, err := queryOCSP(server, body)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		status, err := ParseOCSPResponse(data, cert, issuer, at)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("OCSP %q: %v", server, err))
+			continue
+		}
+
+		status.URL = server
+		result = append(result, status)
 	}
 
-	buf := bytes.NewBuffer([]byte{})
-	buf.ReadFrom(resp.Body)
+	return result, errors.Join(errs...)
+}
 
-	r, err := ocsp.ParseResponse(buf.Bytes(), issuer)
+// ParseOCSPResponse parses an OCSP response for the certificate and verifies
+// its signature against the issuer. A certificate revoked after a given time
+// is good at that time, and a response whose next update is before that time
+// can only prove the certificate revoked.
+func ParseOCSPResponse(data []byte, cert *x509.Certificate, issuer *x509.Certificate, at time.Time) (*OCSPStatus, error) {
+	r, err := ocsp.ParseResponseForCert(data, cert, issuer)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	if r.Status == ocsp.Revoked && r.RevokedAt.After(at) {
+		r.Status = ocsp.Good
+		r.RevokedAt = time.Time{}
+		r.RevocationReason = ocsp.Unspecified
 	}
 
-	return r.Status == ocsp.Good, nil
+	if r.Status != ocsp.Revoked && !r.NextUpdate.IsZero() && r.NextUpdate.Before(at) {
+		return nil, fmt.Errorf("response is stale: next update was %s", r.NextUpdate.Local())
+	}
+
+	status := &OCSPStatus{
+		Status:           r.Status,
+		RevocationTime:   r.RevokedAt,
+		Reason:           r.RevocationReason,
+		ProducedAt:       r.ProducedAt,
+		ThisUpdate:       r.ThisUpdate,
+		NextUpdate:       r.NextUpdate,
+		ResponderKeyHash: r.ResponderKeyHash,
+		Delegated:        r.Certificate != nil,
+	}
+
+	if len(r.RawResponderName) > 0 {
+		var rdn pkix.RDNSequence
+		if _, err := asn1.Unmarshal(r.RawResponderName, &rdn); err == nil {
+			status.ResponderName = rdn.String()
+		}
+	}
+
+	return status, nil
+}
+
+// queryOCSP sends the request to the server, using GET method for short
+// requests and falling back to POST. Errors of both methods are returned.
+func queryOCSP(server string, body []byte) ([]byte, error) {
+	var getErr error
+	encoded := url.QueryEscape(base64.StdEncoding.EncodeToString(body))
+	if len(encoded) <= maxOCSPGetLength {
+		data, err := doOCSPRequest(http.MethodGet, strings.TrimSuffix(server, "/")+"/"+encoded, nil)
+		if err == nil {
+			return data, nil
+		}
+		getErr = err
+	}
+
+	data, err := doOCSPRequest(http.MethodPost, server, body)
+	if err != nil {
+		return nil, errors.Join(getErr, err)
+	}
+	return data, nil
+}
+
+func doOCSPRequest(method, rawURL string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("OCSP %q: %v", rawURL, err)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/ocsp-request")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OCSP %q: %v", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("OCSP %q: got status code %d", rawURL, resp.StatusCode)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	buf.ReadFrom(resp.Body)
+
+	return buf.Bytes(), nil
 }
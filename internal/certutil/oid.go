@@ -0,0 +1,299 @@
+package certutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/asn1"
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// knownOIDs is the registry of object identifier names used for policies,
+// name attributes, extensions, QC statements and ASN.1 dumps. It can be
+// extended by users with LoadOIDFile.
+var knownOIDs = map[string]string{
+	// name attributes, abbreviated like in distinguished names
+	"2.5.4.3":                    "CN",
+	"2.5.4.4":                    "surname",
+	"2.5.4.5":                    "SERIALNUMBER",
+	"2.5.4.6":                    "C",
+	"2.5.4.7":                    "L",
+	"2.5.4.8":                    "ST",
+	"2.5.4.9":                    "STREET",
+	"2.5.4.10":                   "O",
+	"2.5.4.11":                   "OU",
+	"2.5.4.12":                   "title",
+	"2.5.4.13":                   "description",
+	"2.5.4.15":                   "businessCategory",
+	"2.5.4.16":                   "postalAddress",
+	"2.5.4.17":                   "POSTALCODE",
+	"2.5.4.18":                   "postOfficeBox",
+	"2.5.4.20":                   "telephoneNumber",
+	"2.5.4.41":                   "name",
+	"2.5.4.42":                   "givenName",
+	"2.5.4.43":                   "initials",
+	"2.5.4.44":                   "generationQualifier",
+	"2.5.4.45":                   "x500UniqueIdentifier",
+	"2.5.4.46":                   "dnQualifier",
+	"2.5.4.54":                   "dmdName",
+	"2.5.4.65":                   "pseudonym",
+	"2.5.4.72":                   "role",
+	"2.5.4.97":                   "organizationIdentifier",
+	"1.2.840.113549.1.9.1":       "emailAddress",
+	"0.9.2342.19200300.100.1.1":  "UID",
+	"0.9.2342.19200300.100.1.25": "DC",
+	"1.3.6.1.4.1.311.60.2.1.1":   "jurisdictionOfIncorporationLocalityName",
+	"1.3.6.1.4.1.311.60.2.1.2":   "jurisdictionOfIncorporationStateOrProvinceName",
+	"1.3.6.1.4.1.311.60.2.1.3":   "jurisdictionOfIncorporationCountryName",
+
+	// subject directory attributes (RFC 3739)
+	"1.3.6.1.5.5.7.9.1": "Date of Birth",
+	"1.3.6.1.5.5.7.9.2": "Place of Birth",
+	"1.3.6.1.5.5.7.9.3": "Gender",
+	"1.3.6.1.5.5.7.9.4": "Country of Citizenship",
+	"1.3.6.1.5.5.7.9.5": "Country of Residence",
+
+	// extensions
+	"2.5.29.9":                "subjectDirectoryAttributes",
+	"2.5.29.14":               "subjectKeyIdentifier",
+	"2.5.29.15":               "keyUsage",
+	"2.5.29.17":               "subjectAltName",
+	"2.5.29.18":               "issuerAltName",
+	"2.5.29.19":               "basicConstraints",
+	"2.5.29.30":               "nameConstraints",
+	"2.5.29.31":               "cRLDistributionPoints",
+	"2.5.29.32":               "certificatePolicies",
+	"2.5.29.32.0":             "anyPolicy",
+	"2.5.29.33":               "policyMappings",
+	"2.5.29.35":               "authorityKeyIdentifier",
+	"2.5.29.36":               "policyConstraints",
+	"2.5.29.37":               "extKeyUsage",
+	"2.5.29.46":               "freshestCRL",
+	"2.5.29.54":               "inhibitAnyPolicy",
+	"1.3.6.1.5.5.7.1.1":       "authorityInfoAccess",
+	"1.3.6.1.5.5.7.1.3":       "qcStatements",
+	"1.3.6.1.5.5.7.1.11":      "subjectInfoAccess",
+	"1.3.6.1.5.5.7.1.24":      "tlsFeature",
+	"1.3.6.1.4.1.11129.2.4.2": "signedCertificateTimestampList",
+	"1.3.6.1.4.1.11129.2.4.3": "ctPrecertificatePoison",
+	"1.3.6.1.5.5.7.48.1":      "ocsp",
+	"1.3.6.1.5.5.7.48.1.5":    "ocspNoCheck",
+	"1.3.6.1.5.5.7.48.2":      "caIssuers",
+	"1.3.6.1.5.5.7.48.3":      "timeStamping",
+	"1.3.6.1.5.5.7.48.5":      "caRepository",
+	"1.3.6.1.5.5.7.2.1":       "cps",
+	"1.3.6.1.5.5.7.2.2":       "unotice",
+
+	// otherName types
+	"1.3.6.1.5.5.7.8.5": "XMPP Address",
+	"1.3.6.1.5.5.7.8.7": "SRVName",
+	"1.3.6.1.5.5.7.8.9": "SmtpUTF8Mailbox",
+
+	// extended key usages
+	"1.3.6.1.5.5.7.3.1": "serverAuth",
+	"1.3.6.1.5.5.7.3.2": "clientAuth",
+	"1.3.6.1.5.5.7.3.3": "codeSigning",
+	"1.3.6.1.5.5.7.3.4": "emailProtection",
+	"1.3.6.1.5.5.7.3.8": "timeStamping",
+	"1.3.6.1.5.5.7.3.9": "OCSPSigning",
+
+	// CA/Browser Forum policies
+	"2.23.140.1.1":     "Extended Validation",
+	"2.23.140.1.2.1":   "Domain Validated",
+	"2.23.140.1.2.2":   "Organizational Validation",
+	"2.23.140.1.2.3":   "Individual Validation",
+	"2.23.140.1.3":     "EV Code Signing Certificate",
+	"2.23.140.1.4.1":   "Code Signing Certificate",
+	"2.23.140.1.4.2":   "Timestamp Certificate",
+	"2.23.140.1.5.1.1": "S/MIME Mailbox Validated Legacy",
+	"2.23.140.1.5.1.2": "S/MIME Mailbox Validated Multipurpose",
+	"2.23.140.1.5.1.3": "S/MIME Mailbox Validated Strict",
+	"2.23.140.1.5.2.1": "S/MIME Organization Validated Legacy",
+	"2.23.140.1.5.2.2": "S/MIME Organization Validated Multipurpose",
+	"2.23.140.1.5.2.3": "S/MIME Organization Validated Strict",
+	"2.23.140.1.5.3.1": "S/MIME Sponsor Validated Legacy",
+	"2.23.140.1.5.3.2": "S/MIME Sponsor Validated Multipurpose",
+	"2.23.140.1.5.3.3": "S/MIME Sponsor Validated Strict",
+	"2.23.140.1.5.4.1": "S/MIME Individual Validated Legacy",
+	"2.23.140.1.5.4.2": "S/MIME Individual Validated Multipurpose",
+	"2.23.140.1.5.4.3": "S/MIME Individual Validated Strict",
+
+	// CA-specific policies
+	"1.3.6.1.4.1.44947.1.1.1":      "ISRG Domain Validated",
+	"2.16.840.1.114412.2.1":        "DigiCert Extended Validation",
+	"1.3.6.1.4.1.6449.1.2.1.5.1":   "Sectigo Extended Validation",
+	"1.3.6.1.4.1.4146.1.1":         "GlobalSign Extended Validation",
+	"2.16.840.1.114028.10.1.2":     "Entrust Extended Validation",
+	"2.16.840.1.114413.1.7.23.3":   "GoDaddy Extended Validation",
+	"2.16.840.1.114414.1.7.23.3":   "Starfield Extended Validation",
+	"2.16.578.1.26.1.3.3":          "Buypass Extended Validation",
+	"2.16.756.1.89.1.2.1.1":        "SwissSign Extended Validation",
+	"1.3.159.1.17.1":               "Actalis Extended Validation",
+	"1.3.6.1.4.1.8024.0.2.100.1.2": "QuoVadis Extended Validation",
+	"1.2.616.1.113527.2.5.1.1":     "Certum Extended Validation",
+	"1.3.6.1.4.1.4788.2.202.1":     "D-TRUST Extended Validation",
+	"1.3.6.1.4.1.7879.13.24.1":     "T-Systems Extended Validation",
+
+	// ETSI policies
+	"0.4.0.194112.1.0": "ETSI QCP-n",
+	"0.4.0.194112.1.1": "ETSI QCP-l",
+	"0.4.0.194112.1.2": "ETSI QCP-n-qscd",
+	"0.4.0.194112.1.3": "ETSI QCP-l-qscd",
+	"0.4.0.194112.1.4": "ETSI QEVCP-w",
+	"0.4.0.194112.1.5": "ETSI QNCP-w",
+	"0.4.0.194112.1.6": "ETSI QNCP-w-gen",
+
+	// ETSI QC statements
+	"0.4.0.1862.1.1":     "ETSI QcCompliance",
+	"0.4.0.1862.1.2":     "ETSI QcLimitValue",
+	"0.4.0.1862.1.3":     "ETSI QcRetentionPeriod",
+	"0.4.0.1862.1.4":     "ETSI QcSSCD",
+	"0.4.0.1862.1.5":     "ETSI QcPDS",
+	"0.4.0.1862.1.6":     "ETSI QcType",
+	"0.4.0.1862.1.6.1":   "ETSI qct-esign",
+	"0.4.0.1862.1.6.2":   "ETSI qct-eseal",
+	"0.4.0.1862.1.6.3":   "ETSI qct-web",
+	"0.4.0.1862.1.7":     "ETSI QcCClegislation",
+	"0.4.0.19495.2":      "ETSI PSD2",
+	"0.4.0.19495.1.1":    "PSP_AS",
+	"0.4.0.19495.1.2":    "PSP_PI",
+	"0.4.0.19495.1.3":    "PSP_AI",
+	"0.4.0.19495.1.4":    "PSP_IC",
+	"1.3.6.1.5.5.7.11.1": "PKIX QCSyntax-v1",
+	"1.3.6.1.5.5.7.11.2": "PKIX QCSyntax-v2",
+	"0.4.0.194121.1.1":   "ETSI Natural Person",
+	"0.4.0.194121.1.2":   "ETSI Legal Person",
+	"0.4.0.194121.1.3":   "eIDAS Natural Person",
+	"0.4.0.194121.1.4":   "eIDAS Legal Person",
+
+	// Microsoft
+	"1.3.6.1.4.1.311.2.1.21":  "Microsoft Individual Code Signing",
+	"1.3.6.1.4.1.311.2.1.22":  "Microsoft Commercial Code Signing",
+	"1.3.6.1.4.1.311.10.3.1":  "Microsoft Trust List Signing",
+	"1.3.6.1.4.1.311.10.3.3":  "Microsoft Server Gated Crypto",
+	"1.3.6.1.4.1.311.10.3.4":  "Microsoft Encrypted File System",
+	"1.3.6.1.4.1.311.10.3.12": "Microsoft Document Signing",
+	"1.3.6.1.4.1.311.10.3.13": "Microsoft Lifetime Signing",
+	"1.3.6.1.4.1.311.20.2":    "Microsoft Certificate Template Name",
+	"1.3.6.1.4.1.311.20.2.2":  "Microsoft Smart Card Logon",
+	"1.3.6.1.4.1.311.20.2.3":  "UPN",
+	"1.3.6.1.4.1.311.21.1":    "Microsoft CA Version",
+	"1.3.6.1.4.1.311.21.7":    "Microsoft Certificate Template",
+	"1.3.6.1.4.1.311.21.10":   "Microsoft Application Policies",
+
+	// Apple
+	"1.2.840.113635.100.4.1":    "Apple Code Signing",
+	"1.2.840.113635.100.5.1":    "Apple Certificate Policy",
+	"1.2.840.113635.100.6.1.2":  "Apple iPhone Developer",
+	"1.2.840.113635.100.6.1.4":  "Apple iPhone Distribution",
+	"1.2.840.113635.100.6.1.13": "Apple Developer ID Application",
+	"1.2.840.113635.100.6.1.14": "Apple Developer ID Installer",
+	"1.2.840.113635.100.6.2.1":  "Apple WWDR Intermediate",
+	"1.2.840.113635.100.6.2.6":  "Apple Developer ID Intermediate",
+
+	// algorithms
+	"1.2.840.113549.1.1.1":    "rsaEncryption",
+	"1.2.840.113549.1.1.5":    "sha1WithRSAEncryption",
+	"1.2.840.113549.1.1.10":   "rsassaPss",
+	"1.2.840.113549.1.1.11":   "sha256WithRSAEncryption",
+	"1.2.840.113549.1.1.12":   "sha384WithRSAEncryption",
+	"1.2.840.113549.1.1.13":   "sha512WithRSAEncryption",
+	"1.2.840.10045.2.1":       "ecPublicKey",
+	"1.2.840.10045.3.1.7":     "prime256v1",
+	"1.3.132.0.34":            "secp384r1",
+	"1.3.132.0.35":            "secp521r1",
+	"1.2.840.10045.4.3.2":     "ecdsa-with-SHA256",
+	"1.2.840.10045.4.3.3":     "ecdsa-with-SHA384",
+	"1.2.840.10045.4.3.4":     "ecdsa-with-SHA512",
+	"1.3.101.110":             "X25519",
+	"1.3.101.111":             "X448",
+	"1.3.101.112":             "Ed25519",
+	"1.3.101.113":             "Ed448",
+	"2.16.840.1.101.3.4.2.1":  "sha256",
+	"2.16.840.1.101.3.4.2.2":  "sha384",
+	"2.16.840.1.101.3.4.2.3":  "sha512",
+	"2.16.840.1.101.3.4.3.17": "ML-DSA-44",
+	"2.16.840.1.101.3.4.3.18": "ML-DSA-65",
+	"2.16.840.1.101.3.4.3.19": "ML-DSA-87",
+
+	// PKCS #7
+	"1.2.840.113549.1.7.1": "data",
+	"1.2.840.113549.1.7.2": "signedData",
+}
+
+// OIDName returns name of a known object identifier, or an empty string.
+// Both asn1.ObjectIdentifier and x509.OID are accepted.
+func OIDName(oid fmt.Stringer) string {
+	return knownOIDs[oid.String()]
+}
+
+// OIDNameOrString returns name of a known object identifier, or its dotted form.
+func OIDNameOrString(oid fmt.Stringer) string {
+	if name := OIDName(oid); name != "" {
+		return name
+	}
+	return oid.String()
+}
+
+// builtinOIDs keeps names of the registry before any are registered.
+var builtinOIDs = maps.Clone(knownOIDs)
+
+// resetOIDs removes names added with RegisterOID and LoadOIDFile,
+// restoring built-in names.
+func resetOIDs() {
+	knownOIDs = maps.Clone(builtinOIDs)
+}
+
+// RegisterOID adds a name of the object identifier to the registry,
+// replacing a built-in name.
+func RegisterOID(oid asn1.ObjectIdentifier, name string) {
+	knownOIDs[oid.String()] = name
+}
+
+// LoadOIDFile registers object identifier names from a file. Each line
+// contains an OID followed by its name; empty lines and lines starting
+// with "#" are ignored.
+func LoadOIDFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		oid, err := parseOID(fields[0])
+		if err != nil || len(fields) < 2 {
+			return fmt.Errorf("%s:%d: expected an OID followed by a name", path, n)
+		}
+
+		RegisterOID(oid, strings.Join(fields[1:], " "))
+	}
+
+	return scanner.Err()
+}
+
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid OID %q", s)
+	}
+
+	oid := make(asn1.ObjectIdentifier, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid OID %q", s)
+		}
+		oid[i] = n
+	}
+	return oid, nil
+}
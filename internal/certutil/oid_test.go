@@ -0,0 +1,63 @@
+package certutil_test
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+func TestLoadOIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oids")
+	content := "# enterprise policies\n1.3.6.1.4.1.99999.1.1  Example Corp Internal TLS\n\n1.3.6.1.4.1.99999.2 Example Corp Device\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("cannot write OID file: %s", err)
+	}
+
+	t.Cleanup(certutil.ResetOIDs)
+	if err := certutil.LoadOIDFile(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tc := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.Policies = []x509.OID{mustOID(t, "2.23.140.1.2.2"), mustOID(t, "1.3.6.1.4.1.99999.1.1"), mustOID(t, "1.3.6.1.4.1.99999.1.2")}
+		leaf.UnknownExtKeyUsage = append(leaf.UnknownExtKeyUsage, asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 2}, asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 10, 3, 12})
+	})
+	cert := internal.NewCertificate(tc.leaf)
+
+	got := []string{cert.CertificatePolicies(), cert.ExtKeyUsage()}
+	want := []string{
+		"Organizational Validation\nExample Corp Internal TLS\n1.3.6.1.4.1.99999.1.2",
+		"serverAuth\nExample Corp Device\nMicrosoft Document Signing",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	certutil.ResetOIDs()
+	if name := certutil.OIDName(asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1, 1}); name != "" {
+		t.Fatalf("OIDName() after reset = %q, want empty", name)
+	}
+	if name := certutil.OIDName(asn1.ObjectIdentifier{2, 23, 140, 1, 2, 2}); name != "Organizational Validation" {
+		t.Fatalf("OIDName() after reset = %q, want built-in name", name)
+	}
+}
+
+func TestLoadOIDFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oids")
+	if err := os.WriteFile(path, []byte("1.2.3 Valid\n1.2.x Invalid\n"), 0o600); err != nil {
+		t.Fatalf("cannot write OID file: %s", err)
+	}
+
+	t.Cleanup(certutil.ResetOIDs)
+	want := fmt.Errorf("%s:2: expected an OID followed by a name", path)
+	if diff := cmp.Diff(want, certutil.LoadOIDFile(path), equateErrorMessage); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
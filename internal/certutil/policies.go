@@ -0,0 +1,119 @@
+package certutil
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"unicode/utf16"
+)
+
+var (
+	OIDCPSQualifier        = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 2, 1}
+	OIDUserNoticeQualifier = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 2, 2}
+)
+
+// PolicyInformation defines a policy of the Certificate Policies extension
+// with its qualifiers.
+type PolicyInformation struct {
+	ID          asn1.ObjectIdentifier
+	CPSURIs     []string
+	UserNotices []UserNotice
+}
+
+// UserNotice defines a user notice policy qualifier.
+type UserNotice struct {
+	// Organization and NoticeNumbers define the noticeRef.
+	Organization  string
+	NoticeNumbers []int
+	ExplicitText  string
+}
+
+type policyInformation struct {
+	ID         asn1.ObjectIdentifier
+	Qualifiers []policyQualifierInfo `asn1:"optional"`
+}
+
+type policyQualifierInfo struct {
+	ID        asn1.ObjectIdentifier
+	Qualifier asn1.RawValue
+}
+
+type noticeReference struct {
+	Organization  asn1.RawValue
+	NoticeNumbers []int
+}
+
+// GetCertificatePolicies returns policies of the Certificate Policies extension.
+func GetCertificatePolicies(cert *x509.Certificate) ([]PolicyInformation, error) {
+	for _, e := range cert.Extensions {
+		if !e.Id.Equal(OIDCertificatePoliciesExt) {
+			continue
+		}
+
+		var raw []policyInformation
+		if _, err := asn1.Unmarshal(e.Value, &raw); err != nil {
+			return nil, fmt.Errorf("cannot parse certificate policies: %w", err)
+		}
+
+		policies := make([]PolicyInformation, len(raw))
+		for i, p := range raw {
+			policies[i].ID = p.ID
+			for _, q := range p.Qualifiers {
+				switch {
+				case q.ID.Equal(OIDCPSQualifier):
+					policies[i].CPSURIs = append(policies[i].CPSURIs, string(q.Qualifier.Bytes))
+				case q.ID.Equal(OIDUserNoticeQualifier):
+					notice, err := parseUserNotice(q.Qualifier)
+					if err != nil {
+						return nil, err
+					}
+					policies[i].UserNotices = append(policies[i].UserNotices, notice)
+				}
+			}
+		}
+		return policies, nil
+	}
+	return nil, nil
+}
+
+func parseUserNotice(v asn1.RawValue) (UserNotice, error) {
+	notice := UserNotice{}
+	if !v.IsCompound {
+		return notice, fmt.Errorf("cannot parse user notice")
+	}
+
+	// both fields are optional and distinguishable by their tags
+	for data := v.Bytes; len(data) > 0; {
+		var field asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &field)
+		if err != nil {
+			return notice, fmt.Errorf("cannot parse user notice: %w", err)
+		}
+		data = rest
+
+		if field.Tag != asn1.TagSequence {
+			notice.ExplicitText = decodeDisplayText(field)
+			continue
+		}
+
+		var ref noticeReference
+		if _, err := asn1.Unmarshal(field.FullBytes, &ref); err != nil {
+			return notice, fmt.Errorf("cannot parse notice reference: %w", err)
+		}
+		notice.Organization = decodeDisplayText(ref.Organization)
+		notice.NoticeNumbers = ref.NoticeNumbers
+	}
+	return notice, nil
+}
+
+// decodeDisplayText returns text of IA5String, VisibleString, BMPString or UTF8String.
+func decodeDisplayText(v asn1.RawValue) string {
+	if v.Tag == asn1.TagBMPString && len(v.Bytes)%2 == 0 {
+		u := make([]uint16, len(v.Bytes)/2)
+		for i := range u {
+			u[i] = uint16(v.Bytes[2*i])<<8 | uint16(v.Bytes[2*i+1])
+		}
+		return string(utf16.Decode(u))
+	}
+	return string(v.Bytes)
+}
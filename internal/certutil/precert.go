@@ -0,0 +1,44 @@
+package certutil
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+
+	ctx509 "github.com/google/certificate-transparency-go/x509"
+)
+
+var OIDCTPoisonExt = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 3}
+
+// IsPrecertificate reports whether the certificate carries the CT poison
+// extension (RFC 6962, section 3.1).
+func IsPrecertificate(cert *x509.Certificate) bool {
+	for _, e := range cert.Extensions {
+		if e.Id.Equal(OIDCTPoisonExt) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPrecertificate reports whether the certificate was issued from the
+// precertificate, i.e. their TBSCertificates are equal once the SCT list
+// and the poison extension are removed.
+func MatchPrecertificate(cert *x509.Certificate, precert *x509.Certificate) (bool, error) {
+	if !IsPrecertificate(precert) {
+		return false, fmt.Errorf("certificate is not a precertificate")
+	}
+
+	tbs, err := ctx509.RemoveSCTList(cert.RawTBSCertificate)
+	if err != nil {
+		return false, err
+	}
+
+	preTBS, err := ctx509.RemoveCTPoison(precert.RawTBSCertificate)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(tbs, preTBS), nil
+}
@@ -0,0 +1,53 @@
+package certutil_test
+
+import (
+	"testing"
+
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+func TestPrecertificate(t *testing.T) {
+	log := newTestLog(t, "log.example", "Operator A")
+	loadTestLogList(t, log)
+
+	tc := newTestChain(t, nil)
+	precert := internal.NewCertificate(tc.precertificate(t))
+	tc.embedSCTs(t, log)
+	cert := tc.certificate()
+
+	if !precert.IsPrecertificate() {
+		t.Fatal("expected precertificate to be detected")
+	}
+	if cert.IsPrecertificate() {
+		t.Fatal("expected final certificate not to be a precertificate")
+	}
+
+	if match, err := cert.MatchPrecertificate(precert); err != nil || !match {
+		t.Fatalf("MatchPrecertificate() = %v, %v, want true", match, err)
+	}
+
+	sct := cert.SignedCertificateTimestamps()[0]
+	if status, err := cert.VerifyPrecertificateSCT(precert, sct); status != certutil.SCTVerified {
+		t.Fatalf("VerifyPrecertificateSCT() = %s (%v), want %s", status, err, certutil.SCTVerified)
+	}
+
+	t.Run("other precertificate", func(t *testing.T) {
+		other := newTestChain(t, nil)
+		otherPrecert := internal.NewCertificate(other.precertificate(t))
+
+		if match, err := cert.MatchPrecertificate(otherPrecert); err != nil || match {
+			t.Fatalf("MatchPrecertificate() = %v, %v, want false", match, err)
+		}
+
+		if status, _ := cert.VerifyPrecertificateSCT(otherPrecert, sct); status != certutil.SCTInvalid {
+			t.Fatalf("VerifyPrecertificateSCT() = %s, want %s", status, certutil.SCTInvalid)
+		}
+	})
+
+	t.Run("not a precertificate", func(t *testing.T) {
+		if _, err := cert.MatchPrecertificate(cert); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package certutil
+
+import (
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+)
+
+// minimum key sizes considered secure
+const (
+	minRSAKeySize   = 2048
+	minDSAKeySize   = 2048
+	minECDSAKeySize = 224
+)
+
+// PublicKeyInfo defines details of a subject public key.
+type PublicKeyInfo struct {
+	Algorithm string
+	// Size is the key size in bits, or zero when not applicable.
+	Size  int
+	Curve string
+	// Exponent is the public exponent of a RSA key.
+	Exponent int
+	// Data is the RSA modulus, the EC point or the raw key.
+	Data []byte
+
+	// Errors lists reasons why the key is invalid or compromised.
+	Errors []string
+	// Warnings lists reasons why the key is considered weak.
+	Warnings []string
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm struct {
+		Algorithm  asn1.ObjectIdentifier
+		Parameters asn1.RawValue `asn1:"optional"`
+	}
+	PublicKey asn1.BitString
+}
+
+// GetPublicKeyInfo returns details of the subject public key of the certificate.
+func GetPublicKeyInfo(cert *x509.Certificate) *PublicKeyInfo {
+	info := &PublicKeyInfo{Algorithm: cert.PublicKeyAlgorithm.String()}
+
+	var spki subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &spki); err == nil {
+		info.Data = spki.PublicKey.RightAlign()
+		// algorithms crypto/x509 cannot parse are named using the registry
+		if cert.PublicKeyAlgorithm == x509.UnknownPublicKeyAlgorithm {
+			info.Algorithm = OIDNameOrString(spki.Algorithm.Algorithm)
+		}
+	}
+
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		info.Size = pub.N.BitLen()
+		info.Exponent = pub.E
+		info.Data = pub.N.Bytes()
+
+		if info.Size < minRSAKeySize {
+			info.Warnings = append(info.Warnings, fmt.Sprintf("RSA key smaller than %d bits", minRSAKeySize))
+		}
+		switch {
+		case pub.E == 1:
+			info.Errors = append(info.Errors, "RSA public exponent 1 does not encrypt")
+		case pub.E%2 == 0:
+			info.Errors = append(info.Errors, fmt.Sprintf("even RSA public exponent %d", pub.E))
+		case pub.E < 65537:
+			info.Warnings = append(info.Warnings, fmt.Sprintf("small RSA public exponent %d", pub.E))
+		}
+		if IsROCAVulnerable(pub.N) {
+			info.Errors = append(info.Errors, "RSA key vulnerable to ROCA (CVE-2017-15361)")
+		}
+		if IsDebianWeakKey(pub.N) {
+			info.Errors = append(info.Errors, "Debian weak RSA key (CVE-2008-0166)")
+		}
+
+	case *ecdsa.PublicKey:
+		info.Size = pub.Curve.Params().BitSize
+		info.Curve = pub.Curve.Params().Name
+		if point, err := pub.Bytes(); err == nil {
+			info.Data = point
+		}
+
+		if info.Size < minECDSAKeySize {
+			info.Warnings = append(info.Warnings, fmt.Sprintf("elliptic curve %s is weaker than %d bits", info.Curve, minECDSAKeySize))
+		}
+
+	case ed25519.PublicKey:
+		info.Size = 256
+		info.Data = pub
+
+	case *dsa.PublicKey:
+		info.Size = pub.P.BitLen()
+
+		if info.Size < minDSAKeySize {
+			info.Warnings = append(info.Warnings, fmt.Sprintf("DSA key smaller than %d bits", minDSAKeySize))
+		}
+	}
+
+	return info
+}
@@ -3,6 +3,7 @@ package certutil
 import (
 	"encoding/asn1"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 )
@@ -13,34 +14,143 @@ var (
 	OIDQCSyntaxV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 11, 1}
 	OIDQCSyntaxV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 11, 2}
 
-	knownStatements = map[string]statementDescription{
-		"0.4.0.1862.1.1": {"ETSI QcCompliance", nil},
-		"0.4.0.1862.1.5": {"ETSI QcPDS", &[]struct {
+	// statementSyntaxes defines statement information syntaxes by statement OID.
+	// Statement names are taken from the OID registry.
+	statementSyntaxes = map[string]interface{}{
+		"0.4.0.1862.1.2": &QcLimitValue{},
+		"0.4.0.1862.1.3": new(QcRetentionPeriod),
+		"0.4.0.1862.1.5": &[]struct {
 			URL      string
 			Language string
-		}{}},
-		"0.4.0.1862.1.6": {"ETSI QcType", &etsiQcType{}},
+		}{},
+		"0.4.0.1862.1.6": &QcType{},
+		"0.4.0.1862.1.7": &QcCClegislation{},
+		"0.4.0.19495.2":  &PSD2QcType{},
+
+		"1.3.6.1.5.5.7.11.1": &SemanticsInformation{},
+		"1.3.6.1.5.5.7.11.2": &SemanticsInformation{},
 	}
+
+	// optionalInformation lists statements whose information is OPTIONAL.
+	optionalInformation = []asn1.ObjectIdentifier{OIDQCSyntaxV1, OIDQCSyntaxV2}
 )
 
-type etsiQcType []asn1.ObjectIdentifier
+// QcLimitValue defines the limit on the value of transactions for which
+// the certificate can be used (ETSI EN 319 412-5).
+type QcLimitValue struct {
+	// Currency is an alphabetic (PrintableString) or numeric (INTEGER) ISO 4217 code.
+	Currency asn1.RawValue
+	Amount   int
+	Exponent int
+}
+
+// CurrencyCode returns the ISO 4217 currency code.
+func (v QcLimitValue) CurrencyCode() string {
+	if v.Currency.Tag == asn1.TagInteger {
+		var n int
+		if _, err := asn1.Unmarshal(v.Currency.FullBytes, &n); err == nil {
+			return fmt.Sprintf("%03d", n)
+		}
+	}
+	return string(v.Currency.Bytes)
+}
+
+// Value returns the limit as a decimal number (amount × 10^exponent).
+func (v QcLimitValue) Value() string {
+	amount := big.NewInt(int64(v.Amount))
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(v.Exponent, -v.Exponent))), nil)
+	if v.Exponent >= 0 {
+		return amount.Mul(amount, scale).String()
+	}
+	return new(big.Rat).SetFrac(amount, scale).FloatString(-v.Exponent)
+}
+
+func (v QcLimitValue) String() string {
+	return fmt.Sprintf("%s %s", v.Value(), v.CurrencyCode())
+}
+
+// QcRetentionPeriod defines the number of years registration information
+// is retained after the certificate expires (ETSI EN 319 412-5).
+type QcRetentionPeriod int
+
+func (p QcRetentionPeriod) String() string {
+	return fmt.Sprintf("%d years", int(p))
+}
+
+// QcCClegislation lists countries under whose legislation the certificate
+// is issued as qualified (ETSI EN 319 412-5).
+type QcCClegislation []string
+
+func (l QcCClegislation) String() string {
+	return strings.Join(l, ", ")
+}
+
+// SemanticsInformation defines the semantics of the subject name (RFC 3739).
+type SemanticsInformation struct {
+	SemanticsIdentifier asn1.ObjectIdentifier `asn1:"optional"`
+	// NameRegistrationAuthorities are GeneralNames.
+	NameRegistrationAuthorities []asn1.RawValue `asn1:"optional"`
+}
+
+func (s SemanticsInformation) String() string {
+	return s.Format(DN.RFC4514)
+}
 
-func (s etsiQcType) String() string {
-	switch s[0].String() {
-	case "0.4.0.1862.1.6.1":
-		return "ETSI qct-esign"
-	case "0.4.0.1862.1.6.2":
-		return "ETSI qct-eseal"
-	case "0.4.0.1862.1.6.3":
-		return "ETSI qct-web"
+// Format returns the semantics identifier and registration authorities,
+// with directory names formatted by a given function.
+func (s SemanticsInformation) Format(formatDN func(DN) string) string {
+	parts := []string{}
+	if len(s.SemanticsIdentifier) > 0 {
+		parts = append(parts, OIDNameOrString(s.SemanticsIdentifier))
+	}
+	names := &AltNames{}
+	for _, name := range s.NameRegistrationAuthorities {
+		if err := names.add(name); err != nil {
+			parts = append(parts, "Registration Authority: "+err.Error())
+		}
 	}
+	for _, name := range names.Strings(formatDN) {
+		parts = append(parts, "Registration Authority: "+name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PSD2QcType defines roles of a payment service provider and its national
+// competent authority (ETSI TS 119 495).
+type PSD2QcType struct {
+	Roles   []RoleOfPSP
+	NCAName string `asn1:"utf8"`
+	NCAID   string `asn1:"utf8"`
+}
+
+// RoleOfPSP defines a role of a payment service provider.
+type RoleOfPSP struct {
+	ID   asn1.ObjectIdentifier
+	Name string `asn1:"utf8"`
+}
 
-	return s[0].String()
+func (t PSD2QcType) String() string {
+	roles := make([]string, len(t.Roles))
+	for i, r := range t.Roles {
+		roles[i] = r.Name
+	}
+	return fmt.Sprintf("Roles: %s, NCA: %s (%s)", strings.Join(roles, ", "), t.NCAName, t.NCAID)
 }
 
-type statementDescription struct {
-	Name   string
-	Syntax interface{}
+// QcType lists types of the qualified certificate: electronic signature,
+// seal or website authentication (ETSI EN 319 412-5).
+type QcType []asn1.ObjectIdentifier
+
+func (s QcType) String() string {
+	if len(s) == 0 {
+		return "none"
+	}
+
+	types := make([]string, len(s))
+	for i, oid := range s {
+		types[i] = OIDNameOrString(oid)
+	}
+	return strings.Join(types, ", ")
 }
 
 type QCStatement struct {
@@ -70,31 +180,56 @@ func (s *QCStatement) ParseInformation(val interface{}) error {
 	return nil
 }
 
+// Information returns the decoded statement information, or nil when the
+// statement has no known information syntax.
+func (s *QCStatement) Information() (interface{}, error) {
+	syntax, ok := statementSyntaxes[s.ID.String()]
+	if !ok {
+		return nil, nil
+	}
+
+	if len(s.RawInformation.FullBytes) == 0 {
+		for _, oid := range optionalInformation {
+			if s.ID.Equal(oid) {
+				return nil, nil
+			}
+		}
+	}
+
+	val := reflect.New(reflect.ValueOf(syntax).Elem().Type()).Interface()
+	if err := s.ParseInformation(val); err != nil {
+		return nil, err
+	}
+	return reflect.ValueOf(val).Elem().Interface(), nil
+}
+
 func (s *QCStatement) String() string {
+	return s.Format(DN.RFC4514)
+}
+
+// Format returns the statement name followed by its information, with
+// directory names formatted by a given function.
+func (s *QCStatement) Format(formatDN func(DN) string) string {
 	b := strings.Builder{}
-	if desc, ok := knownStatements[s.ID.String()]; ok {
-		b.WriteString(desc.Name)
-
-		if desc.Syntax != nil {
-			val := reflect.New(reflect.ValueOf(desc.Syntax).Elem().Type()).Interface()
-			b.WriteString(" = ")
-
-			err := s.ParseInformation(val)
-			if err != nil {
-				b.WriteString(err.Error())
-			} else {
-				v := reflect.ValueOf(val).Elem()
-				if str, ok := v.Interface().(fmt.Stringer); ok {
-					b.WriteString(str.String())
-				} else if v.Len() == 1 {
-					b.WriteString(fmt.Sprintf("%s", v.Index(0).Interface()))
-				} else {
-					b.WriteString(fmt.Sprintf("%s", v.Interface()))
-				}
-			}
+	b.WriteString(OIDNameOrString(s.ID))
+
+	info, err := s.Information()
+	switch {
+	case err != nil:
+		b.WriteString(" = ")
+		b.WriteString(err.Error())
+	case info != nil:
+		b.WriteString(" = ")
+		v := reflect.ValueOf(info)
+		if si, ok := info.(SemanticsInformation); ok {
+			b.WriteString(si.Format(formatDN))
+		} else if str, ok := info.(fmt.Stringer); ok {
+			b.WriteString(str.String())
+		} else if v.Len() == 1 {
+			b.WriteString(fmt.Sprintf("%s", v.Index(0).Interface()))
+		} else {
+			b.WriteString(fmt.Sprintf("%s", v.Interface()))
 		}
-	} else {
-		b.WriteString(s.ID.String())
 	}
 
 	return b.String()
@@ -0,0 +1,25 @@
+package certutil
+
+import "fmt"
+
+// revocationReasons defines names of CRLReason codes (RFC 5280, section 5.3.1).
+var revocationReasons = map[int]string{
+	0:  "unspecified",
+	1:  "keyCompromise",
+	2:  "cACompromise",
+	3:  "affiliationChanged",
+	4:  "superseded",
+	5:  "cessationOfOperation",
+	6:  "certificateHold",
+	8:  "removeFromCRL",
+	9:  "privilegeWithdrawn",
+	10: "aACompromise",
+}
+
+// RevocationReasonString returns a name of the revocation reason code.
+func RevocationReasonString(reason int) string {
+	if name, ok := revocationReasons[reason]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", reason)
+}
@@ -0,0 +1,242 @@
+package certutil
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// GeneralName types (RFC 5280, section 4.2.1.6).
+const (
+	nameTypeOther         = 0
+	nameTypeEmail         = 1
+	nameTypeDNS           = 2
+	nameTypeX400Address   = 3
+	nameTypeDirectoryName = 4
+	nameTypeEDIPartyName  = 5
+	nameTypeURI           = 6
+	nameTypeIP            = 7
+	nameTypeRegisteredID  = 8
+)
+
+// otherName types with a known string syntax.
+var (
+	OIDMicrosoftUPN    = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}
+	OIDXMPPAddr        = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 8, 5}
+	OIDSRVName         = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 8, 7}
+	OIDSmtpUTF8Mailbox = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 8, 9}
+)
+
+// AltNames defines names of the Subject Alternative Name extension.
+type AltNames struct {
+	DNSNames       []string
+	EmailAddresses []string
+	URIs           []string
+	IPAddresses    []net.IP
+	DirectoryNames []DN
+	RegisteredIDs  []asn1.ObjectIdentifier
+	OtherNames     []OtherName
+	RawNames       []RawName
+}
+
+// OtherName defines an otherName of the Subject Alternative Name extension.
+type OtherName struct {
+	Type asn1.ObjectIdentifier
+	// Value is the decoded string for known types, hex-encoded DER otherwise.
+	Value string
+}
+
+// RawName defines a name without a text form, such as x400Address,
+// ediPartyName or a name of an unknown type.
+type RawName struct {
+	Tag int
+	// Value is the hex-encoded DER of the name.
+	Value string
+}
+
+// Type returns the name of the GeneralName type, or its tag when unknown.
+func (n RawName) Type() string {
+	switch n.Tag {
+	case nameTypeX400Address:
+		return "x400Address"
+	case nameTypeEDIPartyName:
+		return "ediPartyName"
+	}
+	return fmt.Sprintf("[%d]", n.Tag)
+}
+
+type otherName struct {
+	Type asn1.ObjectIdentifier
+	// Value is the value wrapped in an explicit [0] tag.
+	Value asn1.RawValue
+}
+
+// GetSubjectAltNames returns all names of the Subject Alternative Name extension,
+// including ones crypto/x509 does not parse.
+func GetSubjectAltNames(cert *x509.Certificate) (*AltNames, error) {
+	return getAltNames(cert, OIDSubjectAltNameExt, "subject alternative name")
+}
+
+// GetIssuerAltNames returns all names of the Issuer Alternative Name extension.
+func GetIssuerAltNames(cert *x509.Certificate) (*AltNames, error) {
+	return getAltNames(cert, OIDIssuerAltNameExt, "issuer alternative name")
+}
+
+func getAltNames(cert *x509.Certificate, oid asn1.ObjectIdentifier, extName string) (*AltNames, error) {
+	names := &AltNames{}
+	for _, e := range cert.Extensions {
+		if !e.Id.Equal(oid) {
+			continue
+		}
+
+		var seq asn1.RawValue
+		if rest, err := asn1.Unmarshal(e.Value, &seq); err != nil || len(rest) > 0 || !seq.IsCompound {
+			return nil, fmt.Errorf("cannot parse %s", extName)
+		}
+
+		if err := names.addAll(seq.Bytes); err != nil {
+			return nil, fmt.Errorf("cannot parse %s: %w", extName, err)
+		}
+	}
+	return names, nil
+}
+
+// addAll adds all names of concatenated DER-encoded GeneralName values.
+func (n *AltNames) addAll(data []byte) error {
+	for len(data) > 0 {
+		var v asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &v)
+		if err != nil {
+			return err
+		}
+		data = rest
+
+		if err := n.add(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *AltNames) add(v asn1.RawValue) error {
+	if v.Class != asn1.ClassContextSpecific {
+		return fmt.Errorf("unexpected tag %d", v.Tag)
+	}
+
+	switch v.Tag {
+	case nameTypeDNS:
+		n.DNSNames = append(n.DNSNames, string(v.Bytes))
+	case nameTypeEmail:
+		n.EmailAddresses = append(n.EmailAddresses, string(v.Bytes))
+	case nameTypeURI:
+		n.URIs = append(n.URIs, string(v.Bytes))
+	case nameTypeIP:
+		n.IPAddresses = append(n.IPAddresses, net.IP(v.Bytes))
+
+	case nameTypeDirectoryName:
+		name, err := ParseDN(v.Bytes)
+		if err != nil {
+			return fmt.Errorf("cannot parse directory name: %w", err)
+		}
+		n.DirectoryNames = append(n.DirectoryNames, name)
+
+	case nameTypeRegisteredID:
+		oid, err := unmarshalImplicitOID(v.Bytes)
+		if err != nil {
+			return fmt.Errorf("cannot parse registered ID: %w", err)
+		}
+		n.RegisteredIDs = append(n.RegisteredIDs, oid)
+
+	case nameTypeOther:
+		// otherName is IMPLICIT, so its SEQUENCE tag has to be restored
+		var on otherName
+		seq := asn1.RawValue{Tag: asn1.TagSequence, IsCompound: true, Bytes: v.Bytes}
+		der, err := asn1.Marshal(seq)
+		if err == nil {
+			_, err = asn1.Unmarshal(der, &on)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot parse other name: %w", err)
+		}
+		n.OtherNames = append(n.OtherNames, OtherName{Type: on.Type, Value: otherNameValue(on)})
+
+	default:
+		n.RawNames = append(n.RawNames, RawName{Tag: v.Tag, Value: strings.ToUpper(hex.EncodeToString(v.FullBytes))})
+	}
+	return nil
+}
+
+// Strings returns names prefixed with their type, with directory names
+// formatted by a given function.
+func (n *AltNames) Strings(formatDN func(DN) string) []string {
+	result := []string{}
+	for _, v := range n.DNSNames {
+		result = append(result, "DNS: "+v)
+	}
+	for _, v := range n.IPAddresses {
+		result = append(result, "IP: "+v.String())
+	}
+	for _, v := range n.EmailAddresses {
+		result = append(result, "Email: "+v)
+	}
+	for _, v := range n.URIs {
+		result = append(result, "URI: "+v)
+	}
+	for _, v := range n.DirectoryNames {
+		result = append(result, "Directory Name: "+formatDN(v))
+	}
+	for _, v := range n.RegisteredIDs {
+		result = append(result, "Registered ID: "+OIDNameOrString(v))
+	}
+	for _, v := range n.OtherNames {
+		result = append(result, fmt.Sprintf("Other Name: %s: %s", OIDNameOrString(v.Type), v.Value))
+	}
+	for _, v := range n.RawNames {
+		result = append(result, fmt.Sprintf("Raw Name: %s: %s", v.Type(), v.Value))
+	}
+	return result
+}
+
+func unmarshalImplicitOID(b []byte) (asn1.ObjectIdentifier, error) {
+	der, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagOID, Bytes: b})
+	if err != nil {
+		return nil, err
+	}
+
+	var oid asn1.ObjectIdentifier
+	_, err = asn1.Unmarshal(der, &oid)
+	return oid, err
+}
+
+func otherNameValue(on otherName) string {
+	var v asn1.RawValue
+	if _, err := asn1.Unmarshal(on.Value.Bytes, &v); err != nil {
+		return strings.ToUpper(hex.EncodeToString(on.Value.Bytes))
+	}
+
+	for _, oid := range []asn1.ObjectIdentifier{OIDMicrosoftUPN, OIDXMPPAddr, OIDSRVName, OIDSmtpUTF8Mailbox} {
+		if on.Type.Equal(oid) && v.Class == asn1.ClassUniversal && !v.IsCompound {
+			return string(v.Bytes)
+		}
+	}
+	return strings.ToUpper(hex.EncodeToString(v.FullBytes))
+}
+
+// SPIFFEID defines a SPIFFE ID split into the trust domain and the path.
+type SPIFFEID struct {
+	TrustDomain string
+	Path        string
+}
+
+// ParseSPIFFEID returns the SPIFFE ID of a URI, or nil if it is not one.
+func ParseSPIFFEID(uri string) *SPIFFEID {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "spiffe" || u.Host == "" {
+		return nil
+	}
+	return &SPIFFEID{TrustDomain: u.Host, Path: u.Path}
+}
@@ -1,27 +1,90 @@
 package certutil
 
 import (
+	"bytes"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/asn1"
+	"fmt"
 	"net/http"
 
 	ct "github.com/google/certificate-transparency-go"
+	"github.com/google/certificate-transparency-go/ctutil"
 	ctlogs "github.com/google/certificate-transparency-go/loglist3"
 	cttls "github.com/google/certificate-transparency-go/tls"
 	ctx509 "github.com/google/certificate-transparency-go/x509"
 	ctx509util "github.com/google/certificate-transparency-go/x509util"
+	"golang.org/x/crypto/ocsp"
 )
 
-var oidExtensionCT = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+var (
+	OIDSCTListExt      = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+	oidExtensionOCSPCT = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 5}
+)
 
 var ctLogList *ctlogs.LogList
 
+// SCTSource defines how a SCT was delivered.
+type SCTSource int
+
+const (
+	SCTEmbedded SCTSource = iota
+	SCTFromTLS
+	SCTFromOCSP
+)
+
+func (s SCTSource) String() string {
+	switch s {
+	case SCTFromTLS:
+		return "TLS extension"
+	case SCTFromOCSP:
+		return "OCSP response"
+	default:
+		return "embedded in certificate"
+	}
+}
+
+// SCT defines a Signed Certificate Timestamp along with its delivery method.
+type SCT struct {
+	ct.SignedCertificateTimestamp
+	Source SCTSource
+}
+
 // GetSCTs returns Signed Certificate Timestamps from certificate.
-func GetSCTs(cert *x509.Certificate) (result []ct.SignedCertificateTimestamp) {
+func GetSCTs(cert *x509.Certificate) []ct.SignedCertificateTimestamp {
+	return getSCTListExtension(cert.Extensions, OIDSCTListExt)
+}
+
+// GetOCSPSCTs returns Signed Certificate Timestamps from OCSP response.
+// The response signature is not verified.
+func GetOCSPSCTs(data []byte) []ct.SignedCertificateTimestamp {
+	r, err := ocsp.ParseResponse(data, nil)
+	if err != nil {
+		return nil
+	}
+
+	return getSCTListExtension(r.Extensions, oidExtensionOCSPCT)
+}
+
+// ParseSCTs parses serialized Signed Certificate Timestamps, e.g. delivered
+// in the TLS extension. Malformed SCTs are skipped.
+func ParseSCTs(serializedSCTs [][]byte) (result []ct.SignedCertificateTimestamp) {
+	for _, serializedSCT := range serializedSCTs {
+		var sct ct.SignedCertificateTimestamp
+		if rest, err := cttls.Unmarshal(serializedSCT, &sct); err != nil || len(rest) > 0 {
+			continue
+		}
+		result = append(result, sct)
+	}
+
+	return
+}
+
+func getSCTListExtension(extensions []pkix.Extension, oid asn1.ObjectIdentifier) (result []ct.SignedCertificateTimestamp) {
 	var serializedSCTs []byte
 
-	for _, e := range cert.Extensions {
-		if !e.Id.Equal(oidExtensionCT) {
+	for _, e := range extensions {
+		if !e.Id.Equal(oid) {
 			continue
 		}
 		if _, err := asn1.Unmarshal(e.Value, &serializedSCTs); err != nil {
@@ -45,13 +108,112 @@ func GetSCTs(cert *x509.Certificate) (result []ct.SignedCertificateTimestamp) {
 	return
 }
 
+// SCTLog defines a CT log which issued a SCT.
+type SCTLog struct {
+	Description string
+	Operator    string
+	// Key is the DER-encoded public key of the log.
+	Key   []byte
+	URL   string
+	State *ctlogs.LogStates
+	// Tiled reports whether the log implements Static CT API instead of RFC 6962.
+	Tiled bool
+}
+
 // GetSCTLog return a SCT log relevant to given SCT.
-func GetSCTLog(sct ct.SignedCertificateTimestamp) *ctlogs.Log {
+func GetSCTLog(sct ct.SignedCertificateTimestamp) *SCTLog {
 	if ctLogList == nil {
 		return nil
 	}
 
-	return ctLogList.FindLogByKeyHash(sct.LogID.KeyID)
+	for _, op := range ctLogList.Operators {
+		for _, log := range op.Logs {
+			if bytes.Equal(log.LogID, sct.LogID.KeyID[:]) {
+				return &SCTLog{log.Description, op.Name, log.Key, log.URL, log.State, false}
+			}
+		}
+		for _, log := range op.TiledLogs {
+			if bytes.Equal(log.LogID, sct.LogID.KeyID[:]) {
+				return &SCTLog{log.Description, op.Name, log.Key, log.MonitoringURL, log.State, true}
+			}
+		}
+	}
+
+	return nil
+}
+
+// SCTStatus defines result of SCT signature verification.
+type SCTStatus int
+
+const (
+	SCTUnknownLog SCTStatus = iota
+	SCTVerified
+	SCTInvalid
+	// SCTUnverifiable means the SCT could not be verified, e.g. because
+	// the issuer needed for an embedded SCT is not available.
+	SCTUnverifiable
+)
+
+func (s SCTStatus) String() string {
+	switch s {
+	case SCTVerified:
+		return "verified"
+	case SCTInvalid:
+		return "invalid"
+	case SCTUnverifiable:
+		return "unverifiable"
+	default:
+		return "unknown log"
+	}
+}
+
+// VerifySCT verifies the SCT signature using the public key of the log
+// which issued it. Embedded SCTs are verified over the reconstructed
+// precertificate, which requires the issuer of the certificate. SCTs
+// delivered otherwise are verified over the certificate itself.
+func VerifySCT(sct ct.SignedCertificateTimestamp, cert *x509.Certificate, issuer *x509.Certificate, embedded bool) (SCTStatus, error) {
+	log := GetSCTLog(sct)
+	if log == nil {
+		return SCTUnknownLog, nil
+	}
+
+	pubKey, err := ctx509.ParsePKIXPublicKey(log.Key)
+	if err != nil {
+		return SCTUnknownLog, fmt.Errorf("invalid log public key: %v", err)
+	}
+
+	chain, err := toCTChain(cert, issuer)
+	if err != nil {
+		return SCTInvalid, err
+	}
+
+	if embedded && len(chain) < 2 {
+		return SCTUnverifiable, fmt.Errorf("issuer not present in chain")
+	}
+
+	if err := ctutil.VerifySCT(pubKey, chain, &sct, embedded); err != nil {
+		return SCTInvalid, err
+	}
+
+	return SCTVerified, nil
+}
+
+// toCTChain converts certificates to the certificate-transparency-go
+// representation, omitting nil ones.
+func toCTChain(certs ...*x509.Certificate) ([]*ctx509.Certificate, error) {
+	result := []*ctx509.Certificate{}
+	for _, cert := range certs {
+		if cert == nil {
+			continue
+		}
+
+		c, err := ctx509.ParseCertificate(cert.Raw)
+		if ctx509.IsFatal(err) {
+			return nil, fmt.Errorf("failed to parse certificate: %v", err)
+		}
+		result = append(result, c)
+	}
+	return result, nil
 }
 
 func init() {
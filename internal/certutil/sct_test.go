@@ -0,0 +1,333 @@
+package certutil_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	ct "github.com/google/certificate-transparency-go"
+	"github.com/google/certificate-transparency-go/ctutil"
+	"github.com/google/certificate-transparency-go/loglist3"
+	cttls "github.com/google/certificate-transparency-go/tls"
+	ctx509 "github.com/google/certificate-transparency-go/x509"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestVerifySCT(t *testing.T) {
+	log1 := newTestLog(t, "log1.example", "Operator A")
+	log2 := newTestLog(t, "log2.example", "Operator B")
+	unknownLog := newTestLog(t, "log3.example", "Operator C")
+	loadTestLogList(t, log1, log2)
+
+	tc := newTestChain(t, nil)
+	tc.embedSCTs(t, log1, log2, unknownLog)
+	cert := tc.certificate()
+
+	scts := cert.SignedCertificateTimestamps()
+	if len(scts) != 3 {
+		t.Fatalf("expected 3 SCTs, got %d", len(scts))
+	}
+
+	want := []certutil.SCTStatus{certutil.SCTVerified, certutil.SCTVerified, certutil.SCTUnknownLog}
+	for i, sct := range scts {
+		if got, err := cert.VerifySCT(sct); got != want[i] {
+			t.Errorf("SCT #%d: got %s (%v), want %s", i+1, got, err, want[i])
+		}
+	}
+
+	t.Run("tampered signature", func(t *testing.T) {
+		sct := scts[0]
+		sct.Timestamp++
+		if got, err := cert.VerifySCT(sct); got != certutil.SCTInvalid || err == nil {
+			t.Fatalf("got %s (%v), want %s", got, err, certutil.SCTInvalid)
+		}
+	})
+
+	t.Run("issuer not in chain", func(t *testing.T) {
+		leaf := internal.NewCertificate(tc.leaf)
+		if got, _ := leaf.VerifySCT(scts[0]); got != certutil.SCTUnverifiable {
+			t.Fatalf("got %s, want %s", got, certutil.SCTUnverifiable)
+		}
+
+		for _, r := range leaf.CTCompliance(time.Now()) {
+			if r.Compliant || !r.Unknown {
+				t.Errorf("%s: got compliant %v, unknown %v, want unknown", r.Policy, r.Compliant, r.Unknown)
+			}
+		}
+	})
+}
+
+func TestSignedCertificateTimestamps_Delivery(t *testing.T) {
+	tlsLog := newTestLog(t, "tls.example", "Operator A")
+	ocspLog := newTestLog(t, "ocsp.example", "Operator B")
+	embeddedLog := newTestLog(t, "embedded.example", "Operator C")
+	loadTestLogList(t, tlsLog, ocspLog, embeddedLog)
+
+	tc := newTestChain(t, nil)
+	tc.embedSCTs(t, embeddedLog)
+
+	entry := &ct.TimestampedEntry{
+		EntryType: ct.X509LogEntryType,
+		X509Entry: &ct.ASN1Cert{Data: tc.leaf.Raw},
+	}
+
+	tlsSCT, err := cttls.Marshal(tlsLog.sign(t, entry, time.Now()))
+	if err != nil {
+		t.Fatalf("cannot marshal SCT: %s", err)
+	}
+
+	ocspSCTs, err := asn1.Marshal(marshalSCTList(t, ocspLog.sign(t, entry, time.Now())))
+	if err != nil {
+		t.Fatalf("cannot marshal SCT list: %s", err)
+	}
+
+	staple, err := ocsp.CreateResponse(tc.intermediate, tc.intermediate, ocsp.Response{
+		Status:          ocsp.Good,
+		SerialNumber:    tc.leaf.SerialNumber,
+		ThisUpdate:      time.Now().Add(-time.Hour),
+		NextUpdate:      time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 5}, Value: ocspSCTs}},
+	}, tc.intermediateKey)
+	if err != nil {
+		t.Fatalf("cannot create OCSP response: %s", err)
+	}
+
+	addr := startTLSServer(t, tc, func(c *tls.Certificate) {
+		c.SignedCertificateTimestamps = [][]byte{tlsSCT}
+		c.OCSPStaple = staple
+	})
+
+	cert, err := internal.GetCertificate(&url.URL{Scheme: "tcp", Host: addr})
+	if err != nil {
+		t.Fatalf("cannot get certificate: %s", err)
+	}
+
+	scts := cert.SignedCertificateTimestamps()
+	want := []certutil.SCTSource{certutil.SCTEmbedded, certutil.SCTFromTLS, certutil.SCTFromOCSP}
+	if len(scts) != len(want) {
+		t.Fatalf("expected %d SCTs, got %d", len(want), len(scts))
+	}
+
+	for i, sct := range scts {
+		if sct.Source != want[i] {
+			t.Errorf("SCT #%d: got source %s, want %s", i+1, sct.Source, want[i])
+		}
+		if got, err := cert.VerifySCT(sct); got != certutil.SCTVerified {
+			t.Errorf("SCT #%d: got %s (%v), want %s", i+1, got, err, certutil.SCTVerified)
+		}
+	}
+}
+
+func TestCTCompliance(t *testing.T) {
+	logA1 := newTestLog(t, "a1.example", "Operator A")
+	logA2 := newTestLog(t, "a2.example", "Operator A")
+	logB := newTestLog(t, "b.example", "Operator B")
+	logC := newTestLog(t, "c.example", "Operator C")
+
+	retired := &loglist3.LogStates{Retired: &loglist3.LogState{Timestamp: time.Now().Add(time.Hour)}}
+	retiredA := newTestLog(t, "retired-a.example", "Operator A")
+	retiredA.state = retired
+	retiredB := newTestLog(t, "retired-b.example", "Operator B")
+	retiredB.state = retired
+
+	loadTestLogList(t, logA1, logA2, logB, logC, retiredA, retiredB)
+
+	longLived := func(c *x509.Certificate) { c.NotAfter = c.NotBefore.Add(365 * 24 * time.Hour) }
+
+	cases := []struct {
+		name   string
+		modify func(*x509.Certificate)
+		logs   []*testLog
+		chrome bool
+		apple  bool
+	}{
+		{name: "no SCTs"},
+		{name: "two operators", logs: []*testLog{logA1, logB}, chrome: true, apple: true},
+		{name: "single operator", logs: []*testLog{logA1, logA2}},
+		{name: "long-lived with two SCTs", modify: longLived, logs: []*testLog{logA1, logB}},
+		{name: "long-lived with three SCTs", modify: longLived, logs: []*testLog{logA1, logB, logC}, chrome: true, apple: true},
+		{name: "only retired logs", logs: []*testLog{retiredA, retiredB}, apple: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tc := newTestChain(t, c.modify)
+			if len(c.logs) > 0 {
+				tc.embedSCTs(t, c.logs...)
+			}
+
+			results := tc.certificate().CTCompliance(time.Now().Add(2 * time.Hour))
+			if len(results) != 2 {
+				t.Fatalf("expected 2 results, got %d", len(results))
+			}
+
+			if results[0].Compliant != c.chrome {
+				t.Errorf("Chrome: got %v (%v), want %v", results[0].Compliant, results[0].Reasons, c.chrome)
+			}
+			if results[1].Compliant != c.apple {
+				t.Errorf("Apple: got %v (%v), want %v", results[1].Compliant, results[1].Reasons, c.apple)
+			}
+		})
+	}
+}
+
+func TestVerifySCTInclusion(t *testing.T) {
+	log := newTestLog(t, "log.example", "Operator A")
+	loadTestLogList(t, log)
+
+	tc := newTestChain(t, nil)
+	tc.embedSCTs(t, log)
+	cert := tc.certificate()
+	sct := cert.SignedCertificateTimestamps()[0]
+
+	chain := []*ctx509.Certificate{}
+	for _, c := range []*x509.Certificate{tc.leaf, tc.intermediate} {
+		parsed, err := ctx509.ParseCertificate(c.Raw)
+		if err != nil {
+			t.Fatalf("cannot parse certificate: %s", err)
+		}
+		chain = append(chain, parsed)
+	}
+
+	leafHash, err := ctutil.LeafHash(chain, &sct.SignedCertificateTimestamp, true)
+	if err != nil {
+		t.Fatalf("cannot compute leaf hash: %s", err)
+	}
+
+	otherLeaves := [][]byte{}
+	for i := 0; i < 6; i++ {
+		h := sha256.Sum256([]byte{byte(i)})
+		otherLeaves = append(otherLeaves, h[:])
+	}
+
+	t.Run("included", func(t *testing.T) {
+		leaves := append(append(append([][]byte{}, otherLeaves[:4]...), leafHash[:]), otherLeaves[4:]...)
+		logURL := log.serve(t, leaves)
+
+		proof, err := cert.VerifySCTInclusion(sct, logURL, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if proof.LeafIndex != 4 || proof.TreeSize != 7 {
+			t.Fatalf("unexpected proof: %+v", proof)
+		}
+	})
+
+	t.Run("not included", func(t *testing.T) {
+		logURL := log.serve(t, otherLeaves)
+		if _, err := cert.VerifySCTInclusion(sct, logURL, nil); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+
+	t.Run("tampered tree", func(t *testing.T) {
+		leaves := append([][]byte{leafHash[:]}, otherLeaves...)
+		logURL := log.serve(t, leaves)
+
+		// the server computes the audit path for a different tree than the STH
+		leaves[1] = leaves[2]
+		if _, err := cert.VerifySCTInclusion(sct, logURL, nil); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestVerifySCTInclusion_LogKey(t *testing.T) {
+	// the log is not in the log list
+	log := newTestLog(t, "private.example", "Operator A")
+
+	tc := newTestChain(t, nil)
+	tc.embedSCTs(t, log)
+	cert := tc.certificate()
+	sct := cert.SignedCertificateTimestamps()[0]
+
+	chain := []*ctx509.Certificate{}
+	for _, c := range []*x509.Certificate{tc.leaf, tc.intermediate} {
+		parsed, err := ctx509.ParseCertificate(c.Raw)
+		if err != nil {
+			t.Fatalf("cannot parse certificate: %s", err)
+		}
+		chain = append(chain, parsed)
+	}
+
+	leafHash, err := ctutil.LeafHash(chain, &sct.SignedCertificateTimestamp, true)
+	if err != nil {
+		t.Fatalf("cannot compute leaf hash: %s", err)
+	}
+	other := sha256.Sum256([]byte{0})
+	logURL := log.serve(t, [][]byte{other[:], leafHash[:]})
+
+	proof, err := cert.VerifySCTInclusion(sct, logURL, log.rawKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if proof.LeafIndex != 1 || proof.TreeSize != 2 {
+		t.Fatalf("unexpected proof: %+v", proof)
+	}
+
+	_, err = cert.VerifySCTInclusion(sct, logURL, nil)
+	want := fmt.Errorf("log %s is not in the CT log list: its URL and public key are required", base64.StdEncoding.EncodeToString(log.id[:]))
+	if diff := cmp.Diff(want, err, equateErrorMessage); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	otherLog := newTestLog(t, "other.example", "Operator B")
+	_, err = cert.VerifySCTInclusion(sct, logURL, otherLog.rawKey)
+	want = fmt.Errorf("log key does not match the log ID of the SCT")
+	if diff := cmp.Diff(want, err, equateErrorMessage); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseLogKey(t *testing.T) {
+	log := newTestLog(t, "log.example", "Operator A")
+
+	for name, s := range map[string]string{
+		"base64": base64.StdEncoding.EncodeToString(log.rawKey),
+		"PEM":    string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: log.rawKey})),
+	} {
+		key, id, err := certutil.ParseLogKey(s)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+		if !bytes.Equal(key, log.rawKey) || id != log.id {
+			t.Fatalf("%s: got key %x, ID %x", name, key, id)
+		}
+	}
+
+	_, _, err := certutil.ParseLogKey("AAAA")
+	want := fmt.Errorf("log key: invalid public key")
+	if diff := cmp.Diff(want, err, equateErrorMessage); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseLogID(t *testing.T) {
+	log := newTestLog(t, "log.example", "Operator A")
+	got, err := certutil.ParseLogID(base64.StdEncoding.EncodeToString(log.id[:]))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != log.id {
+		t.Fatalf("got %x, want %x", got, log.id)
+	}
+
+	_, err = certutil.ParseLogID("AAAA")
+	want := fmt.Errorf(`log ID "AAAA": invalid SHA-256 hash`)
+	if diff := cmp.Diff(want, err, equateErrorMessage); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,37 @@
+package certutil
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+)
+
+var OIDTLSFeatureExt = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}
+
+// tlsFeatureStatusRequest defines status_request TLS extension (RFC 6066).
+const tlsFeatureStatusRequest = 5
+
+// GetTLSFeatures returns TLS extensions required by the certificate (RFC 7633).
+func GetTLSFeatures(cert *x509.Certificate) []int {
+	for _, e := range cert.Extensions {
+		if !e.Id.Equal(OIDTLSFeatureExt) {
+			continue
+		}
+
+		var features []int
+		if _, err := asn1.Unmarshal(e.Value, &features); err != nil {
+			return nil
+		}
+		return features
+	}
+	return nil
+}
+
+// HasMustStaple reports whether the certificate requires OCSP stapling.
+func HasMustStaple(cert *x509.Certificate) bool {
+	for _, f := range GetTLSFeatures(cert) {
+		if f == tlsFeatureStatusRequest {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,519 @@
+package certutil
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	ctx509util "github.com/google/certificate-transparency-go/x509util"
+)
+
+// EUListOfTrustedLists defines location of the EU list of trusted lists.
+const EUListOfTrustedLists = "https://ec.europa.eu/tools/lotl/eu-lotl.xml"
+
+// trusted list URIs (ETSI TS 119 612)
+const (
+	serviceTypeQualifiedCA = "http://uri.etsi.org/TrstSvc/Svctype/CA/QC"
+	serviceStatusPrefix    = "http://uri.etsi.org/TrstSvc/TrustedList/Svcstatus/"
+	serviceInfoPrefix      = "http://uri.etsi.org/TrstSvc/TrustedList/SvcInfoExt/"
+	// listOfListsTypeSuffix ends TSL types of lists of trusted lists, e.g. EUlistofthelists.
+	listOfListsTypeSuffix = "listofthelists"
+)
+
+// qualifiedStatuses lists service statuses under which a CA issues
+// qualified certificates, including ones used before eIDAS.
+var qualifiedStatuses = map[string]bool{
+	"granted":                true,
+	"undersupervision":       true,
+	"supervisionincessation": true,
+	"accredited":             true,
+}
+
+// qualifierQcTypes maps qualifiers of the Qualifications extension to QcType
+// values they assign to certificates.
+var qualifierQcTypes = map[string]string{
+	"QCForESig":  "0.4.0.1862.1.6.1",
+	"QCForESeal": "0.4.0.1862.1.6.2",
+	"QCForWSA":   "0.4.0.1862.1.6.3",
+}
+
+// keyUsageBits maps key usage bit names used in trusted lists to key usages.
+var keyUsageBits = map[string]x509.KeyUsage{
+	"digitalSignature": x509.KeyUsageDigitalSignature,
+	"nonRepudiation":   x509.KeyUsageContentCommitment,
+	"keyEncipherment":  x509.KeyUsageKeyEncipherment,
+	"dataEncipherment": x509.KeyUsageDataEncipherment,
+	"keyAgreement":     x509.KeyUsageKeyAgreement,
+	"keyCertSign":      x509.KeyUsageCertSign,
+	"crlSign":          x509.KeyUsageCRLSign,
+	"encipherOnly":     x509.KeyUsageEncipherOnly,
+	"decipherOnly":     x509.KeyUsageDecipherOnly,
+}
+
+// qcTypeServiceInfo maps QcType values to additional service information
+// identifying what qualified certificates a service issues.
+var qcTypeServiceInfo = map[string]string{
+	"0.4.0.1862.1.6.1": "ForeSignatures",
+	"0.4.0.1862.1.6.2": "ForeSeals",
+	"0.4.0.1862.1.6.3": "ForWebSiteAuthentication",
+}
+
+var (
+	oidQcCompliance = asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 1}
+	oidQcType       = asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 6}
+)
+
+// TrustedList defines a trusted list of a member state (ETSI TS 119 612).
+// Signatures of trusted lists are not verified.
+type TrustedList struct {
+	Territory string
+	// Type is the TSL type URI, telling trusted lists from lists of trusted lists.
+	Type      string
+	Providers []TrustServiceProvider
+	// Pointers lists locations of other XML trusted lists.
+	Pointers []string
+}
+
+// IsListOfLists reports whether the list is a list of trusted lists, like
+// the EU list of trusted lists, whose pointers lead to member state lists.
+func (tl *TrustedList) IsListOfLists() bool {
+	return strings.HasSuffix(strings.ToLower(tl.Type), listOfListsTypeSuffix)
+}
+
+// TrustServiceProvider defines a trust service provider of a trusted list.
+type TrustServiceProvider struct {
+	Name     string
+	Services []TrustService
+}
+
+// TrustService defines a trust service with its status history.
+type TrustService struct {
+	Name         string
+	Certificates []*x509.Certificate
+	// History lists the current and previous states, newest first.
+	History []ServiceState
+}
+
+// ServiceState defines a state of a trust service starting at a given time.
+type ServiceState struct {
+	Type           string
+	Status         string
+	Start          time.Time
+	AdditionalInfo []string
+	Qualifications []Qualification
+}
+
+// Qualification defines qualifiers, like QCWithSSCD or NotQualified, which
+// apply to certificates meeting the criteria (ETSI TS 119 612, 5.5.9.2).
+type Qualification struct {
+	Qualifiers []string
+	Criteria   CriteriaList
+}
+
+// CriteriaList defines criteria a certificate has to meet. Criteria other
+// than key usage, policies and nested lists are not supported.
+type CriteriaList struct {
+	// Assert is "all", "atLeastOne" or "none".
+	Assert   string `xml:"assert,attr"`
+	KeyUsage []struct {
+		Bits []struct {
+			Name  string `xml:"name,attr"`
+			Value bool   `xml:",chardata"`
+		} `xml:"KeyUsageBit"`
+	} `xml:"KeyUsage"`
+	PolicySets []struct {
+		Identifiers []string `xml:"PolicyIdentifier>Identifier"`
+	} `xml:"PolicySet"`
+	Lists []CriteriaList `xml:"CriteriaList"`
+}
+
+// Matches reports whether the certificate meets the criteria.
+func (l *CriteriaList) Matches(cert *x509.Certificate) bool {
+	results := []bool{}
+	for _, ku := range l.KeyUsage {
+		matched := true
+		for _, bit := range ku.Bits {
+			matched = matched && (cert.KeyUsage&keyUsageBits[bit.Name] != 0) == bit.Value
+		}
+		results = append(results, matched)
+	}
+	for _, ps := range l.PolicySets {
+		matched := true
+		for _, id := range ps.Identifiers {
+			matched = matched && hasPolicy(cert, strings.TrimPrefix(strings.TrimSpace(id), "urn:oid:"))
+		}
+		results = append(results, matched)
+	}
+	for i := range l.Lists {
+		results = append(results, l.Lists[i].Matches(cert))
+	}
+
+	matched := 0
+	for _, r := range results {
+		if r {
+			matched++
+		}
+	}
+
+	switch l.Assert {
+	case "atLeastOne":
+		return matched > 0
+	case "none":
+		return matched == 0
+	default:
+		return matched == len(results)
+	}
+}
+
+func hasPolicy(cert *x509.Certificate, oid string) bool {
+	for _, p := range cert.Policies {
+		if p.String() == oid {
+			return true
+		}
+	}
+	return false
+}
+
+type xmlTrustedList struct {
+	Territory string `xml:"SchemeInformation>SchemeTerritory"`
+	Type      string `xml:"SchemeInformation>TSLType"`
+	Pointers  []struct {
+		Location         string `xml:"TSLLocation"`
+		OtherInformation []struct {
+			MimeType string
+		} `xml:"AdditionalInformation>OtherInformation"`
+	} `xml:"SchemeInformation>PointersToOtherTSL>OtherTSLPointer"`
+	Providers []struct {
+		Names    []string `xml:"TSPInformation>TSPName>Name"`
+		Services []struct {
+			Information xmlServiceInformation   `xml:"ServiceInformation"`
+			History     []xmlServiceInformation `xml:"ServiceHistory>ServiceHistoryInstance"`
+		} `xml:"TSPServices>TSPService"`
+	} `xml:"TrustServiceProviderList>TrustServiceProvider"`
+}
+
+type xmlServiceInformation struct {
+	Type           string    `xml:"ServiceTypeIdentifier"`
+	Names          []string  `xml:"ServiceName>Name"`
+	Certificates   []string  `xml:"ServiceDigitalIdentity>DigitalId>X509Certificate"`
+	Status         string    `xml:"ServiceStatus"`
+	Start          time.Time `xml:"StatusStartingTime"`
+	AdditionalInfo []string  `xml:"ServiceInformationExtensions>Extension>AdditionalServiceInformation>URI"`
+	Qualifications []struct {
+		Qualifiers []struct {
+			URI string `xml:"uri,attr"`
+		} `xml:"Qualifiers>Qualifier"`
+		Criteria CriteriaList `xml:"CriteriaList"`
+	} `xml:"ServiceInformationExtensions>Extension>Qualifications>QualificationElement"`
+}
+
+// ParseTrustedList parses a XML trusted list.
+func ParseTrustedList(data []byte) (*TrustedList, error) {
+	var raw xmlTrustedList
+	if err := xml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("cannot parse trusted list: %w", err)
+	}
+
+	tl := &TrustedList{Territory: raw.Territory, Type: strings.TrimSpace(raw.Type)}
+	for _, p := range raw.Pointers {
+		for _, info := range p.OtherInformation {
+			if strings.HasSuffix(info.MimeType, "xml") {
+				tl.Pointers = append(tl.Pointers, strings.TrimSpace(p.Location))
+				break
+			}
+		}
+	}
+
+	for _, p := range raw.Providers {
+		provider := TrustServiceProvider{Name: firstName(p.Names)}
+		for _, s := range p.Services {
+			service := TrustService{Name: firstName(s.Information.Names)}
+			for _, c := range s.Information.Certificates {
+				der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(c), ""))
+				if err != nil {
+					continue
+				}
+				if cert, err := x509.ParseCertificate(der); err == nil {
+					service.Certificates = append(service.Certificates, cert)
+				}
+			}
+
+			for _, info := range append([]xmlServiceInformation{s.Information}, s.History...) {
+				state := ServiceState{
+					Type:           strings.TrimSpace(info.Type),
+					Status:         strings.TrimPrefix(strings.TrimSpace(info.Status), serviceStatusPrefix),
+					Start:          info.Start,
+					AdditionalInfo: trimPrefixes(info.AdditionalInfo, serviceInfoPrefix),
+				}
+				for _, q := range info.Qualifications {
+					qualification := Qualification{Criteria: q.Criteria}
+					for _, qualifier := range q.Qualifiers {
+						qualification.Qualifiers = append(qualification.Qualifiers, strings.TrimPrefix(strings.TrimSpace(qualifier.URI), serviceInfoPrefix))
+					}
+					state.Qualifications = append(state.Qualifications, qualification)
+				}
+				service.History = append(service.History, state)
+			}
+			sort.SliceStable(service.History, func(i, j int) bool {
+				return service.History[i].Start.After(service.History[j].Start)
+			})
+
+			provider.Services = append(provider.Services, service)
+		}
+		tl.Providers = append(tl.Providers, provider)
+	}
+
+	return tl, nil
+}
+
+func firstName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(names[0])
+}
+
+func trimPrefixes(values []string, prefix string) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = strings.TrimPrefix(strings.TrimSpace(v), prefix)
+	}
+	return result
+}
+
+// LoadTrustedLists loads a trusted list from a file or URL. When it is a
+// list of trusted lists, member state lists it points to are loaded too;
+// lists which fail to load are reported in the returned error. Pointers of
+// member state lists, which lead back to lists of lists, are not followed.
+func LoadTrustedLists(location string) ([]*TrustedList, error) {
+	root, err := loadTrustedList(location)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := []*TrustedList{root}
+	if !root.IsListOfLists() {
+		return lists, nil
+	}
+
+	errs := []error{}
+	for _, pointer := range root.Pointers {
+		tl, err := loadTrustedList(pointer)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		lists = append(lists, tl)
+	}
+
+	return lists, errors.Join(errs...)
+}
+
+func loadTrustedList(location string) (*TrustedList, error) {
+	data, err := ctx509util.ReadFileOrURL(location, http.DefaultClient)
+	if err != nil {
+		return nil, fmt.Errorf("trusted list %q: %w", location, err)
+	}
+
+	tl, err := ParseTrustedList(data)
+	if err != nil {
+		return nil, fmt.Errorf("trusted list %q: %w", location, err)
+	}
+	return tl, nil
+}
+
+// QualifiedStatus defines whether a certificate was issued by a qualified
+// trust service according to trusted lists.
+type QualifiedStatus struct {
+	Qualified bool
+	// Reason explains why the certificate is not qualified.
+	Reason string
+
+	Territory string
+	Provider  string
+	Service   string
+	// Status is the service status at issuance time.
+	Status string
+	// Qualifiers lists qualifiers the trusted list applies to the certificate, like QCWithSSCD.
+	Qualifiers []string
+}
+
+// CheckQualified checks in trusted lists whether the certificate was issued
+// by a qualified CA service at the time of issuance, for the type of
+// qualified certificate it claims to be.
+func CheckQualified(cert *x509.Certificate, lists []*TrustedList) *QualifiedStatus {
+	result := &QualifiedStatus{}
+
+	compliance, qcTypes := qcClaims(cert)
+	if !compliance {
+		result.Reason = "the certificate does not claim to be qualified (no QcCompliance statement)"
+		return result
+	}
+
+	// a CA may be listed as several services, e.g. one per certificate type
+	var notQualified *QualifiedStatus
+	for _, tl := range lists {
+		for _, p := range tl.Providers {
+			for _, s := range p.Services {
+				if !s.issued(cert) {
+					continue
+				}
+
+				status := &QualifiedStatus{Territory: tl.Territory, Provider: p.Name, Service: s.Name}
+				status.check(s.stateAt(cert.NotBefore), cert, qcTypes)
+				if status.Qualified {
+					return status
+				}
+				if notQualified == nil {
+					notQualified = status
+				}
+			}
+		}
+	}
+
+	if notQualified != nil {
+		return notQualified
+	}
+	result.Reason = "the issuer is not listed in trusted lists"
+	return result
+}
+
+func (r *QualifiedStatus) check(state *ServiceState, cert *x509.Certificate, qcTypes []string) {
+	if state == nil {
+		r.Reason = "the service was not listed at issuance time"
+		return
+	}
+	r.Status = state.Status
+	r.Qualifiers = state.qualifiers(cert)
+
+	// qualifiers of the trusted list take precedence over claims of the certificate
+	if types := qualifiedTypes(r.Qualifiers); len(types) > 0 {
+		qcTypes = types
+	}
+
+	switch {
+	case state.Type != serviceTypeQualifiedCA:
+		r.Reason = fmt.Sprintf("the service was not a qualified CA at issuance time (%s)", state.Type)
+	case !qualifiedStatuses[state.Status]:
+		r.Reason = fmt.Sprintf("the service status at issuance time was %s", state.Status)
+	case slices.Contains(r.Qualifiers, "NotQualified"):
+		r.Reason = "the trusted list qualifies the certificate as not qualified"
+	default:
+		if missing := missingServiceInfo(state.AdditionalInfo, qcTypes); missing != "" {
+			r.Reason = fmt.Sprintf("the service was not qualified %s at issuance time", missing)
+		} else {
+			r.Qualified = true
+		}
+	}
+}
+
+// qualifiers returns qualifiers of qualifications whose criteria the certificate meets.
+func (s *ServiceState) qualifiers(cert *x509.Certificate) []string {
+	result := []string{}
+	for _, q := range s.Qualifications {
+		if q.Criteria.Matches(cert) {
+			result = append(result, q.Qualifiers...)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// qualifiedTypes returns QcType values assigned by qualifiers.
+func qualifiedTypes(qualifiers []string) []string {
+	types := []string{}
+	for _, q := range qualifiers {
+		if t, ok := qualifierQcTypes[q]; ok && !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// issued reports whether the certificate was signed with a key of the service.
+func (s *TrustService) issued(cert *x509.Certificate) bool {
+	for _, c := range s.Certificates {
+		if bytes.Equal(c.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(c) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// stateAt returns the service state in effect at a given time.
+func (s *TrustService) stateAt(t time.Time) *ServiceState {
+	for i := range s.History {
+		if !s.History[i].Start.After(t) {
+			return &s.History[i]
+		}
+	}
+	return nil
+}
+
+// missingServiceInfo returns the additional service information required
+// by the QcTypes but absent in the service state. Services without any
+// additional information are qualified for all types.
+func missingServiceInfo(info, qcTypes []string) string {
+	if len(info) == 0 {
+		return ""
+	}
+
+	for _, t := range qcTypes {
+		required := qcTypeServiceInfo[t]
+		found := false
+		for _, i := range info {
+			found = found || i == required
+		}
+		if required != "" && !found {
+			return required
+		}
+	}
+	return ""
+}
+
+// qcClaims returns whether the certificate has the QcCompliance statement
+// and its QcType values. Certificates without QcType are for electronic
+// signatures (ETSI EN 319 412-5).
+func qcClaims(cert *x509.Certificate) (bool, []string) {
+	compliance, qcTypes := false, []string{}
+	for _, e := range cert.Extensions {
+		if !e.Id.Equal(OIDQCStatementsExt) {
+			continue
+		}
+
+		statements, err := ParseQCStatement(e.Value)
+		if err != nil {
+			return false, nil
+		}
+
+		for _, s := range statements {
+			switch {
+			case s.ID.Equal(oidQcCompliance):
+				compliance = true
+			case s.ID.Equal(oidQcType):
+				var types QcType
+				if s.ParseInformation(&types) == nil {
+					for _, t := range types {
+						qcTypes = append(qcTypes, t.String())
+					}
+				}
+			}
+		}
+	}
+
+	if len(qcTypes) == 0 {
+		qcTypes = append(qcTypes, "0.4.0.1862.1.6.1")
+	}
+	return compliance, qcTypes
+}
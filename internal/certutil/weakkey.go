@@ -0,0 +1,90 @@
+package certutil
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// rocaPrimes lists small primes used by the ROCA fingerprint test.
+var rocaPrimes = []int64{
+	3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67, 71,
+	73, 79, 83, 89, 97, 101, 103, 107, 109, 113, 127, 131, 137, 139, 149, 151,
+	157, 163, 167,
+}
+
+// rocaSubgroups holds, for each of rocaPrimes, residues of powers of 65537.
+var rocaSubgroups []map[int64]bool
+
+// debianBlocklist holds truncated fingerprints of Debian weak RSA keys.
+var debianBlocklist = map[string]bool{}
+
+// IsROCAVulnerable reports whether the RSA modulus has the fingerprint of
+// keys generated by Infineon RSALib (CVE-2017-15361). Such moduli are
+// congruent to a power of 65537 modulo every small prime.
+func IsROCAVulnerable(n *big.Int) bool {
+	if n.Sign() <= 0 {
+		return false
+	}
+
+	r := new(big.Int)
+	for i, p := range rocaPrimes {
+		r.Mod(n, big.NewInt(p))
+		if !rocaSubgroups[i][r.Int64()] {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadDebianBlocklist loads a blocklist of RSA keys generated by the Debian
+// OpenSSL package with a predictable random number generator (CVE-2008-0166),
+// in the format of the openssl-blacklist package. Keys from subsequently
+// loaded files are added to the previously loaded ones.
+func LoadDebianBlocklist(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if _, err := hex.DecodeString(text); err != nil || len(text) != 20 {
+			return fmt.Errorf("%s:%d: expected 20 hex digits of a key fingerprint", path, line)
+		}
+		debianBlocklist[strings.ToLower(text)] = true
+	}
+	return scanner.Err()
+}
+
+// resetDebianBlocklist removes keys of all loaded Debian blocklists.
+func resetDebianBlocklist() {
+	debianBlocklist = map[string]bool{}
+}
+
+// IsDebianWeakKey reports whether the RSA modulus is in loaded Debian
+// blocklists. Blocklists hold the last 80 bits of the SHA-1 hash of the
+// modulus as printed by "openssl rsa -modulus".
+func IsDebianWeakKey(n *big.Int) bool {
+	sum := sha1.Sum([]byte(fmt.Sprintf("Modulus=%X\n", n)))
+	return debianBlocklist[hex.EncodeToString(sum[10:])]
+}
+
+func init() {
+	for _, p := range rocaPrimes {
+		subgroup := map[int64]bool{}
+		for r := int64(1); !subgroup[r]; r = r * 65537 % p {
+			subgroup[r] = true
+		}
+		rocaSubgroups = append(rocaSubgroups, subgroup)
+	}
+}
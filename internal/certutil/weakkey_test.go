@@ -0,0 +1,71 @@
+package certutil_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+func TestDebianWeakKey(t *testing.T) {
+	tc := newTestChain(t, nil)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate key: %s", err)
+	}
+	cert := internal.NewCertificate(signTestCert(t, tc.leafTemplate, tc.intermediate, &key.PublicKey, tc.intermediateKey))
+
+	if cert.PublicKey().Errors != nil {
+		t.Fatalf("unexpected errors: %v", cert.PublicKey().Errors)
+	}
+
+	sum := sha1.Sum([]byte(fmt.Sprintf("Modulus=%X\n", key.N)))
+	path := filepath.Join(t.TempDir(), "blacklist.RSA-2048")
+	data := fmt.Sprintf("# keys generated with predictable PIDs\n%s\n", hex.EncodeToString(sum[10:]))
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("cannot write blocklist: %s", err)
+	}
+	t.Cleanup(certutil.ResetDebianBlocklist)
+	if err := certutil.LoadDebianBlocklist(path); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if diff := cmp.Diff([]string{"Debian weak RSA key (CVE-2008-0166)"}, cert.PublicKey().Errors); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	found := false
+	for _, f := range cert.Lint() {
+		found = found || f.Rule.Name == "weak_key.debian"
+	}
+	if !found {
+		t.Fatal("weak_key.debian finding not reported")
+	}
+
+	certutil.ResetDebianBlocklist()
+	if cert.PublicKey().Errors != nil {
+		t.Fatalf("unexpected errors after reset: %v", cert.PublicKey().Errors)
+	}
+}
+
+func TestLoadDebianBlocklist_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blacklist")
+	if err := os.WriteFile(path, []byte("# header\nnot a fingerprint\n"), 0o600); err != nil {
+		t.Fatalf("cannot write blocklist: %s", err)
+	}
+
+	err := certutil.LoadDebianBlocklist(path)
+	want := fmt.Errorf("%s:2: expected 20 hex digits of a key fingerprint", path)
+	if diff := cmp.Diff(want, err, equateErrorMessage); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
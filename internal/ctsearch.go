@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCTSearchEndpoint is the crt.sh-compatible CT search API used by default.
+const DefaultCTSearchEndpoint = "https://crt.sh/"
+
+// CTSearchEntry defines a certificate found in CT logs.
+type CTSearchEntry struct {
+	ID           int64        `json:"id"`
+	IssuerName   string       `json:"issuer_name"`
+	CommonName   string       `json:"common_name"`
+	NameValue    string       `json:"name_value"`
+	SerialNumber string       `json:"serial_number"`
+	NotBefore    ctSearchTime `json:"not_before"`
+	NotAfter     ctSearchTime `json:"not_after"`
+	LoggedAt     ctSearchTime `json:"entry_timestamp"`
+}
+
+// Names returns names the certificate was issued for.
+func (e *CTSearchEntry) Names() []string {
+	return strings.Split(e.NameValue, "\n")
+}
+
+// ValidityStatus returns whether the certificate is valid at a given time,
+// based on its validity period only.
+func (e *CTSearchEntry) ValidityStatus(at time.Time) string {
+	switch {
+	case at.Before(e.NotBefore.Time):
+		return "not yet valid"
+	case at.After(e.NotAfter.Time):
+		return "expired"
+	default:
+		return "valid"
+	}
+}
+
+// ctSearchTime parses timestamps returned by crt.sh, which are in UTC
+// but lack a time zone.
+type ctSearchTime struct {
+	time.Time
+}
+
+func (t *ctSearchTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+		if v, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			t.Time = v
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid timestamp %q", s)
+}
+
+// SearchCT returns certificates issued for a given name, as found in CT logs
+// by a crt.sh-compatible search API.
+func SearchCT(endpoint string, name string) ([]CTSearchEntry, error) {
+	u, err := ctSearchURL(endpoint, url.Values{"q": {name}, "output": {"json"}, "deduplicate": {"Y"}})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ctSearchGet(u)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []CTSearchEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse CT search results: %v", err)
+	}
+
+	return entries, nil
+}
+
+// GetCTSearchCertificate downloads a certificate with a given ID from
+// a crt.sh-compatible search API.
+func GetCTSearchCertificate(endpoint string, id int64) (*Certificate, error) {
+	u, err := ctSearchURL(endpoint, url.Values{"d": {strconv.FormatInt(id, 10)}})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ctSearchGet(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseCertificate(data, "")
+}
+
+func ctSearchURL(endpoint string, query url.Values) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid CT search endpoint: %v", err)
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func ctSearchGet(u string) ([]byte, error) {
+	resp, err := http.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %q: %v", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to query %q: got status code %d", u, resp.StatusCode)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	buf.ReadFrom(resp.Body)
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,96 @@
+package internal_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+)
+
+const ctSearchResponse = `[
+	{
+		"issuer_ca_id": 1,
+		"issuer_name": "CN=Test Intermediate",
+		"common_name": "example.com",
+		"name_value": "example.com\nwww.example.com",
+		"id": 123,
+		"entry_timestamp": "2024-01-02T03:04:05.678",
+		"not_before": "2024-01-02T00:00:00",
+		"not_after": "2024-04-01T23:59:59",
+		"serial_number": "03"
+	}
+]`
+
+func TestSearchCT(t *testing.T) {
+	tc := newTestChain(t, nil)
+	queries := []string{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+
+		switch {
+		case r.URL.Query().Get("q") == "example.com":
+			w.Write([]byte(ctSearchResponse))
+		case r.URL.Query().Get("d") == "123":
+			pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: tc.leaf.Raw})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	entries, err := internal.SearchCT(srv.URL+"/", "example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	e := entries[0]
+	if e.ID != 123 || e.IssuerName != "CN=Test Intermediate" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+
+	if diff := cmp.Diff([]string{"example.com", "www.example.com"}, e.Names()); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if want := time.Date(2024, 4, 1, 23, 59, 59, 0, time.UTC); !e.NotAfter.Equal(want) {
+		t.Fatalf("NotAfter = %s, want %s", e.NotAfter, want)
+	}
+
+	statuses := map[time.Time]string{
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC): "not yet valid",
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC): "valid",
+		time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC): "expired",
+	}
+	for at, want := range statuses {
+		if got := e.ValidityStatus(at); got != want {
+			t.Errorf("ValidityStatus(%s) = %q, want %q", at, got, want)
+		}
+	}
+
+	cert, err := internal.GetCTSearchCertificate(srv.URL+"/", e.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if diff := cmp.Diff(internal.NewCertificate(tc.leaf), cert); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := internal.GetCTSearchCertificate(srv.URL+"/", 1); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	want := []string{"deduplicate=Y&output=json&q=example.com", "d=123", "d=1"}
+	if diff := cmp.Diff(want, queries); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+// DNFormat defines how distinguished names are printed.
+type DNFormat string
+
+const (
+	// DNMultiline prints one RDN per line, in the encoded order.
+	DNMultiline DNFormat = "multiline"
+	// DNRFC4514 prints a string usable in LDAP (RFC 4514).
+	DNRFC4514 DNFormat = "rfc4514"
+	// DNOpenSSL prints a string usable in the -subj option of OpenSSL.
+	DNOpenSSL DNFormat = "openssl"
+)
+
+// ParseDNFormat returns a distinguished name format with a given name.
+func ParseDNFormat(name string) (DNFormat, error) {
+	switch f := DNFormat(name); f {
+	case DNMultiline, DNRFC4514, DNOpenSSL:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown distinguished name format %q", name)
+}
+
+// Format returns the distinguished name in the format. The zero value
+// formats names like DNMultiline.
+func (f DNFormat) Format(dn certutil.DN) string {
+	switch f {
+	case DNRFC4514:
+		return dn.RFC4514()
+	case DNOpenSSL:
+		return dn.OpenSSL()
+	default:
+		return dn.Multiline()
+	}
+}
+
+// formatInline returns the distinguished name in the format on a single
+// line, joining lines of DNMultiline with ", ".
+func (f DNFormat) formatInline(dn certutil.DN) string {
+	return strings.ReplaceAll(f.Format(dn), "\n", ", ")
+}
@@ -0,0 +1,134 @@
+package internal_test
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+func TestDNFormat(t *testing.T) {
+	type atv struct {
+		Type  asn1.ObjectIdentifier
+		Value asn1.RawValue
+	}
+	// slice types named *SET are marshalled as SET OF
+	type rdnSET []atv
+	str := func(tag int, s string) asn1.RawValue {
+		return asn1.RawValue{Tag: tag, Bytes: []byte(s)}
+	}
+
+	parse := func(rdns []rdnSET) certutil.DN {
+		der, err := asn1.Marshal(rdns)
+		if err != nil {
+			t.Fatalf("cannot marshal: %s", err)
+		}
+		dn, err := certutil.ParseDN(der)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return dn
+	}
+
+	dn := parse([]rdnSET{
+		{{asn1.ObjectIdentifier{2, 5, 4, 6}, str(asn1.TagPrintableString, "PL")}},
+		{{asn1.ObjectIdentifier{2, 5, 4, 10}, str(asn1.TagUTF8String, "Acme, Inc.")}},
+		{
+			{asn1.ObjectIdentifier{2, 5, 4, 3}, str(asn1.TagUTF8String, "Jan")},
+			{asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 1}, str(asn1.TagUTF8String, "#jan ")},
+		},
+		{{asn1.ObjectIdentifier{2, 5, 4, 65}, str(asn1.TagUTF8String, "#a/b ")}},
+		{{asn1.ObjectIdentifier{1, 2, 3}, asn1.RawValue{Tag: asn1.TagInteger, Bytes: []byte{7}}}},
+		{{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}, str(asn1.TagIA5String, "jan@example.com")}},
+	})
+
+	ev := parse([]rdnSET{
+		{{asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 60, 2, 1, 3}, str(asn1.TagPrintableString, "PL")}},
+		{{asn1.ObjectIdentifier{2, 5, 4, 15}, str(asn1.TagPrintableString, "Private Organization")}},
+		{{asn1.ObjectIdentifier{2, 5, 4, 5}, str(asn1.TagPrintableString, "0000012345")}},
+		{{asn1.ObjectIdentifier{2, 5, 4, 9}, str(asn1.TagUTF8String, "Prosta 1")}},
+		{{asn1.ObjectIdentifier{2, 5, 4, 17}, str(asn1.TagUTF8String, "00-001")}},
+		{{asn1.ObjectIdentifier{2, 5, 4, 97}, str(asn1.TagUTF8String, "VATPL-1234567890")}},
+		{{asn1.ObjectIdentifier{2, 5, 4, 3}, str(asn1.TagUTF8String, "example.com")}},
+	})
+
+	cases := []struct {
+		name   string
+		format internal.DNFormat
+		dn     certutil.DN
+		want   string
+	}{
+		{
+			name:   "multiline",
+			format: internal.DNMultiline,
+			dn:     dn,
+			want:   "C=PL\nO=Acme, Inc.\nCN=Jan + UID=#jan \npseudonym=#a/b \n1.2.3=#020107\nemailAddress=jan@example.com",
+		},
+		{
+			name:   "rfc4514",
+			format: internal.DNRFC4514,
+			dn:     dn,
+			want:   `1.2.840.113549.1.9.1=#160f6a616e406578616d706c652e636f6d,1.2.3=#020107,2.5.4.65=#0c0523612f6220,CN=Jan+UID=\#jan\ ,O=Acme\, Inc.,C=PL`,
+		},
+		{
+			name:   "openssl",
+			format: internal.DNOpenSSL,
+			dn:     dn,
+			want:   `/C=PL/O=Acme, Inc./CN=Jan+UID=#jan /pseudonym=#a\/b /1.2.3=#020107/emailAddress=jan@example.com`,
+		},
+		{
+			name:   "EV multiline",
+			format: internal.DNMultiline,
+			dn:     ev,
+			want:   "jurisdictionOfIncorporationCountryName=PL\nbusinessCategory=Private Organization\nSERIALNUMBER=0000012345\nSTREET=Prosta 1\nPOSTALCODE=00-001\norganizationIdentifier=VATPL-1234567890\nCN=example.com",
+		},
+		{
+			name:   "EV rfc4514",
+			format: internal.DNRFC4514,
+			dn:     ev,
+			want:   `CN=example.com,2.5.4.97=#0c10564154504c2d31323334353637383930,postalCode=00-001,street=Prosta 1,serialNumber=0000012345,businessCategory=Private Organization,1.3.6.1.4.1.311.60.2.1.3=#1302504c`,
+		},
+		{
+			name:   "EV openssl",
+			format: internal.DNOpenSSL,
+			dn:     ev,
+			want:   `/jurisdictionC=PL/businessCategory=Private Organization/serialNumber=0000012345/street=Prosta 1/postalCode=00-001/organizationIdentifier=VATPL-1234567890/CN=example.com`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.format.Format(tc.dn)); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+}
+
+func TestCertificateSubjectDN(t *testing.T) {
+	tc := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.Subject.Organization = []string{"Example"}
+	})
+
+	cert := tc.certificate()
+	if diff := cmp.Diff("CN=example.com,O=Example", internal.DNRFC4514.Format(cert.SubjectDN())); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("/CN=Test Intermediate", internal.DNOpenSSL.Format(cert.IssuerDN())); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseDNFormat(t *testing.T) {
+	if _, err := internal.ParseDNFormat("ldap"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if f, err := internal.ParseDNFormat("rfc4514"); err != nil || f != internal.DNRFC4514 {
+		t.Fatalf("ParseDNFormat() = %q, %v", f, err)
+	}
+}
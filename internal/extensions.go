@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+// knownTLSFeatures defines names of TLS extensions used in the TLS Feature extension.
+var knownTLSFeatures = map[int]string{
+	5:  "status_request (OCSP Must-Staple)",
+	17: "status_request_v2",
+}
+
+// shownExtensions lists extensions presented in dedicated rows.
+var shownExtensions = []asn1.ObjectIdentifier{
+	certutil.OIDSubjectDirectoryAttributesExt,
+	certutil.OIDSubjectKeyIDExt,
+	certutil.OIDKeyUsageExt,
+	certutil.OIDSubjectAltNameExt,
+	certutil.OIDIssuerAltNameExt,
+	certutil.OIDBasicConstraintsExt,
+	certutil.OIDNameConstraintsExt,
+	certutil.OIDCRLDistributionPointsExt,
+	certutil.OIDCertificatePoliciesExt,
+	certutil.OIDPolicyMappingsExt,
+	certutil.OIDAuthorityKeyIDExt,
+	certutil.OIDPolicyConstraintsExt,
+	certutil.OIDExtKeyUsageExt,
+	certutil.OIDFreshestCRLExt,
+	certutil.OIDInhibitAnyPolicyExt,
+	certutil.OIDAuthorityInfoAccessExt,
+	certutil.OIDSubjectInfoAccessExt,
+	certutil.OIDQCStatementsExt,
+	certutil.OIDTLSFeatureExt,
+	certutil.OIDSCTListExt,
+	certutil.OIDCTPoisonExt,
+}
+
+// IsCriticalExtension reports whether the extension is present and marked as critical.
+func (c *Certificate) IsCriticalExtension(oid asn1.ObjectIdentifier) bool {
+	return certutil.IsCriticalExtension(c.cert, oid)
+}
+
+// BasicConstraints returns the CA flag and the path length constraint.
+func (c *Certificate) BasicConstraints() string {
+	if !c.cert.BasicConstraintsValid {
+		return ""
+	}
+
+	if !c.cert.IsCA {
+		return "CA: false"
+	}
+
+	pathLen := "unlimited"
+	if c.cert.MaxPathLen > 0 || c.cert.MaxPathLenZero {
+		pathLen = fmt.Sprint(c.cert.MaxPathLen)
+	}
+	return fmt.Sprintf("CA: true\nPath Length: %s", pathLen)
+}
+
+// NameConstraints returns permitted and excluded name subtrees, with
+// directory names in a given format.
+func (c *Certificate) NameConstraints(dnFormat DNFormat) string {
+	permitted := formatSubtrees(c.cert.PermittedDNSDomains, c.cert.PermittedIPRanges, c.cert.PermittedEmailAddresses, c.cert.PermittedURIDomains)
+	excluded := formatSubtrees(c.cert.ExcludedDNSDomains, c.cert.ExcludedIPRanges, c.cert.ExcludedEmailAddresses, c.cert.ExcludedURIDomains)
+
+	otherPermitted, otherExcluded, err := certutil.GetNameConstraints(c.cert)
+	if err != nil {
+		return err.Error()
+	}
+	permitted = append(permitted, formatGeneralNames(otherPermitted, dnFormat)...)
+	excluded = append(excluded, formatGeneralNames(otherExcluded, dnFormat)...)
+
+	result := []string{}
+	if len(permitted) > 0 {
+		result = append(result, "Permitted:\n"+indentText(strings.Join(permitted, "\n"), 1))
+	}
+	if len(excluded) > 0 {
+		result = append(result, "Excluded:\n"+indentText(strings.Join(excluded, "\n"), 1))
+	}
+	return strings.Join(result, "\n")
+}
+
+func formatSubtrees(dns []string, ips []*net.IPNet, emails, uris []string) []string {
+	result := []string{}
+	for _, v := range dns {
+		result = append(result, "DNS: "+v)
+	}
+	for _, v := range ips {
+		result = append(result, "IP: "+v.String())
+	}
+	for _, v := range emails {
+		result = append(result, "Email: "+v)
+	}
+	for _, v := range uris {
+		result = append(result, "URI: "+v)
+	}
+	return result
+}
+
+// AltNames returns all names of the Subject Alternative Name extension.
+func (c *Certificate) AltNames() (*certutil.AltNames, error) {
+	return certutil.GetSubjectAltNames(c.cert)
+}
+
+// IssuerAltNames returns all names of the Issuer Alternative Name extension.
+func (c *Certificate) IssuerAltNames() (*certutil.AltNames, error) {
+	return certutil.GetIssuerAltNames(c.cert)
+}
+
+// FreshestCRL returns names of delta CRLs covering the certificate.
+func (c *Certificate) FreshestCRL() (*certutil.AltNames, error) {
+	return certutil.GetFreshestCRL(c.cert)
+}
+
+// SubjectInfoAccess returns access descriptions of services offered by the certificate subject.
+func (c *Certificate) SubjectInfoAccess() ([]certutil.AccessDescription, error) {
+	return certutil.GetSubjectInfoAccess(c.cert)
+}
+
+// CRLDistributionPoints returns URLs of CRLs covering the certificate.
+func (c *Certificate) CRLDistributionPoints() []string {
+	return c.cert.CRLDistributionPoints
+}
+
+// AuthorityInfoAccess returns OCSP responder and CA issuer URLs.
+func (c *Certificate) AuthorityInfoAccess() string {
+	result := []string{}
+	for _, v := range c.cert.OCSPServer {
+		result = append(result, "OCSP: "+v)
+	}
+	for _, v := range c.cert.IssuingCertificateURL {
+		result = append(result, "CA Issuers: "+v)
+	}
+	return strings.Join(result, "\n")
+}
+
+// PolicyConstraints returns the requireExplicitPolicy and inhibitPolicyMapping skip certs.
+func (c *Certificate) PolicyConstraints() string {
+	result := []string{}
+	if c.cert.RequireExplicitPolicy > 0 || c.cert.RequireExplicitPolicyZero {
+		result = append(result, fmt.Sprintf("Require Explicit Policy: %d", c.cert.RequireExplicitPolicy))
+	}
+	if c.cert.InhibitPolicyMapping > 0 || c.cert.InhibitPolicyMappingZero {
+		result = append(result, fmt.Sprintf("Inhibit Policy Mapping: %d", c.cert.InhibitPolicyMapping))
+	}
+	return strings.Join(result, "\n")
+}
+
+// PolicyMappings returns issuer domain policies mapped to subject domain policies.
+func (c *Certificate) PolicyMappings() string {
+	result := []string{}
+	for _, m := range c.cert.PolicyMappings {
+		result = append(result, fmt.Sprintf("%s → %s", certutil.OIDNameOrString(m.IssuerDomainPolicy), certutil.OIDNameOrString(m.SubjectDomainPolicy)))
+	}
+	return strings.Join(result, "\n")
+}
+
+// InhibitAnyPolicy returns the number of certificates in the path which may
+// match anyPolicy, or -1 when the extension is missing.
+func (c *Certificate) InhibitAnyPolicy() int {
+	if c.cert.InhibitAnyPolicy > 0 || c.cert.InhibitAnyPolicyZero {
+		return c.cert.InhibitAnyPolicy
+	}
+	return -1
+}
+
+// TLSFeatures returns TLS extensions required by the certificate.
+func (c *Certificate) TLSFeatures() string {
+	result := []string{}
+	for _, f := range certutil.GetTLSFeatures(c.cert) {
+		if name, ok := knownTLSFeatures[f]; ok {
+			result = append(result, name)
+		} else {
+			result = append(result, fmt.Sprint(f))
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// SubjectDirectoryAttributes returns attributes of the certificate subject.
+func (c *Certificate) SubjectDirectoryAttributes() string {
+	attrs, err := certutil.GetSubjectDirectoryAttributes(c.cert)
+	if err != nil {
+		return err.Error()
+	}
+
+	result := []string{}
+	for _, a := range attrs {
+		result = append(result, fmt.Sprintf("%s: %s", a.Name(), strings.Join(a.Values, ", ")))
+	}
+	return strings.Join(result, "\n")
+}
+
+// UnknownExtensions returns extensions which are not presented in dedicated rows.
+func (c *Certificate) UnknownExtensions() []pkix.Extension {
+	result := []pkix.Extension{}
+	for _, e := range c.cert.Extensions {
+		known := false
+		for _, oid := range shownExtensions {
+			known = known || e.Id.Equal(oid)
+		}
+		if !known {
+			result = append(result, e)
+		}
+	}
+	return result
+}
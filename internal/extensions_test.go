@@ -0,0 +1,353 @@
+package internal_test
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+func TestExtensions(t *testing.T) {
+	type attribute struct {
+		Type   asn1.ObjectIdentifier
+		Values []any `asn1:"set"`
+	}
+	dateOfBirth, _ := asn1.MarshalWithParams(time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC), "generalized")
+	attrs, err := asn1.Marshal([]attribute{
+		{Type: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 9, 1}, Values: []any{asn1.RawValue{FullBytes: dateOfBirth}}},
+		{Type: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 9, 4}, Values: []any{"PL", "DE"}},
+	})
+	if err != nil {
+		t.Fatalf("cannot marshal attributes: %s", err)
+	}
+	features, _ := asn1.Marshal([]int{5})
+
+	_, ipRange, _ := net.ParseCIDR("10.0.0.0/8")
+	tc := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.BasicConstraintsValid = true
+		leaf.IsCA = true
+		leaf.MaxPathLenZero = true
+		leaf.PermittedDNSDomainsCritical = true
+		leaf.PermittedDNSDomains = []string{"example.com"}
+		leaf.ExcludedIPRanges = []*net.IPNet{ipRange}
+		leaf.CRLDistributionPoints = []string{"http://crl.example.com/ca.crl"}
+		leaf.OCSPServer = []string{"http://ocsp.example.com"}
+		leaf.IssuingCertificateURL = []string{"http://ca.example.com/ca.crt"}
+		leaf.ExtraExtensions = []pkix.Extension{
+			{Id: certutil.OIDSubjectDirectoryAttributesExt, Value: attrs},
+			{Id: certutil.OIDTLSFeatureExt, Value: features},
+		}
+	})
+	cert := internal.NewCertificate(tc.leaf)
+
+	got := map[string]string{
+		"basic constraints":    cert.BasicConstraints(),
+		"name constraints":     cert.NameConstraints(internal.DNMultiline),
+		"authority info":       cert.AuthorityInfoAccess(),
+		"tls features":         cert.TLSFeatures(),
+		"directory attributes": cert.SubjectDirectoryAttributes(),
+	}
+	want := map[string]string{
+		"basic constraints":    "CA: true\nPath Length: 0",
+		"name constraints":     "Permitted:\n  DNS: example.com\nExcluded:\n  IP: 10.0.0.0/8",
+		"authority info":       "OCSP: http://ocsp.example.com\nCA Issuers: http://ca.example.com/ca.crt",
+		"tls features":         "status_request (OCSP Must-Staple)",
+		"directory attributes": "Date of Birth: 1990-05-17\nCountry of Citizenship: DE, PL",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"http://crl.example.com/ca.crl"}, cert.CRLDistributionPoints()); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if v := cert.InhibitAnyPolicy(); v != -1 {
+		t.Fatalf("InhibitAnyPolicy() = %d, want -1", v)
+	}
+
+	critical := map[string]bool{
+		"basic constraints": cert.IsCriticalExtension(certutil.OIDBasicConstraintsExt),
+		"name constraints":  cert.IsCriticalExtension(certutil.OIDNameConstraintsExt),
+		"tls feature":       cert.IsCriticalExtension(certutil.OIDTLSFeatureExt),
+		"missing":           cert.IsCriticalExtension(certutil.OIDPolicyMappingsExt),
+	}
+	wantCritical := map[string]bool{
+		"basic constraints": true,
+		"name constraints":  true,
+		"tls feature":       false,
+		"missing":           false,
+	}
+	if diff := cmp.Diff(wantCritical, critical); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExtensions_Policies(t *testing.T) {
+	type policyMapping struct {
+		IssuerDomainPolicy, SubjectDomainPolicy asn1.ObjectIdentifier
+	}
+	mappings, err := asn1.Marshal([]policyMapping{
+		{asn1.ObjectIdentifier{2, 23, 140, 1, 2, 1}, asn1.ObjectIdentifier{1, 2, 3, 4}},
+	})
+	if err != nil {
+		t.Fatalf("cannot marshal policy mappings: %s", err)
+	}
+
+	tc := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.BasicConstraintsValid = true
+		leaf.IsCA = true
+		leaf.MaxPathLen = -1
+		leaf.ExtraExtensions = []pkix.Extension{
+			{Id: certutil.OIDPolicyMappingsExt, Critical: true, Value: mappings},
+			// requireExplicitPolicy 0, inhibitPolicyMapping 2
+			{Id: certutil.OIDPolicyConstraintsExt, Critical: true, Value: []byte{0x30, 0x06, 0x80, 0x01, 0x00, 0x81, 0x01, 0x02}},
+			{Id: certutil.OIDInhibitAnyPolicyExt, Critical: true, Value: []byte{0x02, 0x01, 0x01}},
+		}
+	})
+	cert := internal.NewCertificate(tc.leaf)
+
+	got := map[string]any{
+		"basic constraints":  cert.BasicConstraints(),
+		"policy mappings":    cert.PolicyMappings(),
+		"policy constraints": cert.PolicyConstraints(),
+		"inhibit anyPolicy":  cert.InhibitAnyPolicy(),
+	}
+	want := map[string]any{
+		"basic constraints":  "CA: true\nPath Length: unlimited",
+		"policy mappings":    "Domain Validated → 1.2.3.4",
+		"policy constraints": "Require Explicit Policy: 0\nInhibit Policy Mapping: 2",
+		"inhibit anyPolicy":  1,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExtensions_AccessAndAltNames(t *testing.T) {
+	generalName := func(tag int, v string) []byte {
+		der, _ := asn1.MarshalWithParams(v, fmt.Sprintf("tag:%d,ia5", tag))
+		return der
+	}
+	sequence := func(class, tag int, content ...[]byte) []byte {
+		der, _ := asn1.Marshal(asn1.RawValue{Class: class, Tag: tag, IsCompound: true, Bytes: bytes.Join(content, nil)})
+		return der
+	}
+
+	ian := sequence(asn1.ClassUniversal, asn1.TagSequence, generalName(2, "ca.example.com"), generalName(1, "ca@example.com"))
+
+	// DistributionPoint with distributionPoint [0] containing fullName [0]
+	fullName := sequence(asn1.ClassContextSpecific, 0, generalName(6, "http://crl.example.com/delta.crl"))
+	point := sequence(asn1.ClassUniversal, asn1.TagSequence, sequence(asn1.ClassContextSpecific, 0, fullName))
+	freshest := sequence(asn1.ClassUniversal, asn1.TagSequence, point)
+
+	type accessDescription struct {
+		Method   asn1.ObjectIdentifier
+		Location string `asn1:"tag:6,ia5"`
+	}
+	sia, err := asn1.Marshal([]accessDescription{{asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 5}, "http://repo.example.com/"}})
+	if err != nil {
+		t.Fatalf("cannot marshal subject information access: %s", err)
+	}
+
+	tc := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.ExtraExtensions = []pkix.Extension{
+			{Id: certutil.OIDIssuerAltNameExt, Value: ian},
+			{Id: certutil.OIDFreshestCRLExt, Value: freshest},
+			{Id: certutil.OIDSubjectInfoAccessExt, Value: sia},
+		}
+	})
+	cert := internal.NewCertificate(tc.leaf)
+
+	issuerNames, err := cert.IssuerAltNames()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := &certutil.AltNames{DNSNames: []string{"ca.example.com"}, EmailAddresses: []string{"ca@example.com"}}
+	if diff := cmp.Diff(want, issuerNames); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	deltaCRLs, err := cert.FreshestCRL()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(&certutil.AltNames{URIs: []string{"http://crl.example.com/delta.crl"}}, deltaCRLs); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	access, err := cert.SubjectInfoAccess()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	wantAccess := []certutil.AccessDescription{{
+		Method:   asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 5},
+		Location: &certutil.AltNames{URIs: []string{"http://repo.example.com/"}},
+	}}
+	if diff := cmp.Diff(wantAccess, access); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if unknown := cert.UnknownExtensions(); len(unknown) != 0 {
+		t.Fatalf("expected no unknown extensions, got %d", len(unknown))
+	}
+}
+
+func TestExtensions_NameConstraints(t *testing.T) {
+	sequence := func(class, tag int, content ...[]byte) []byte {
+		der, _ := asn1.Marshal(asn1.RawValue{Class: class, Tag: tag, IsCompound: true, Bytes: bytes.Join(content, nil)})
+		return der
+	}
+	subtree := func(base []byte) []byte {
+		return sequence(asn1.ClassUniversal, asn1.TagSequence, base)
+	}
+
+	dns, _ := asn1.MarshalWithParams("example.com", "tag:2,ia5")
+	name, _ := asn1.Marshal(pkix.Name{Country: []string{"PL"}, Organization: []string{"Example"}}.ToRDNSequence())
+	upn, _ := asn1.MarshalWithParams("example.com", "utf8")
+	upnOID, _ := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3})
+
+	nc := sequence(asn1.ClassUniversal, asn1.TagSequence,
+		sequence(asn1.ClassContextSpecific, 0,
+			subtree(dns),
+			subtree(sequence(asn1.ClassContextSpecific, 4, name)),
+		),
+		sequence(asn1.ClassContextSpecific, 1,
+			subtree(sequence(asn1.ClassContextSpecific, 0, upnOID, sequence(asn1.ClassContextSpecific, 0, upn))),
+		),
+	)
+
+	tc := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.ExtraExtensions = []pkix.Extension{{Id: certutil.OIDNameConstraintsExt, Value: nc}}
+	})
+	cert := internal.NewCertificate(tc.leaf)
+
+	want := "Permitted:\n  DNS: example.com\n  Directory Name: /C=PL/O=Example\nExcluded:\n  Other Name: UPN: example.com"
+	if diff := cmp.Diff(want, cert.NameConstraints(internal.DNOpenSSL)); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnknownExtensions(t *testing.T) {
+	type value struct {
+		ID   asn1.ObjectIdentifier
+		Name string `asn1:"utf8"`
+		URI  string `asn1:"tag:6"`
+		N    int
+	}
+	der, err := asn1.Marshal(value{ID: asn1.ObjectIdentifier{2, 5, 4, 3}, Name: "Zażółć", URI: "https://example.com/", N: 42})
+	if err != nil {
+		t.Fatalf("cannot marshal extension: %s", err)
+	}
+
+	oid := asn1.ObjectIdentifier{1, 2, 3, 4}
+	tc := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.ExtraExtensions = []pkix.Extension{{Id: oid, Value: der}}
+	})
+	cert := internal.NewCertificate(tc.leaf)
+
+	want := []pkix.Extension{{Id: oid, Value: der}}
+	if diff := cmp.Diff(want, cert.UnknownExtensions()); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err := certutil.FormatASN1(der)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	wantTree := "SEQUENCE\n" +
+		"  OBJECT IDENTIFIER 2.5.4.3 (CN)\n" +
+		"  UTF8String \"Zażółć\"\n" +
+		"  [6] \"https://example.com/\"\n" +
+		"  INTEGER 42"
+	if diff := cmp.Diff(wantTree, got); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFormatASN1(t *testing.T) {
+	cases := []struct {
+		name string
+		der  []byte
+		want string
+		err  bool
+	}{
+		{name: "encapsulated", der: []byte{0x04, 0x05, 0x30, 0x03, 0x01, 0x01, 0xff}, want: "OCTET STRING (encapsulates)\n  SEQUENCE\n    BOOLEAN true"},
+		{name: "encapsulated primitive", der: []byte{0x04, 0x02, 0x05, 0x00}, want: "OCTET STRING (encapsulates)\n  NULL"},
+		{name: "octet string", der: []byte{0x04, 0x03, 0x05, 0x00, 0x01}, want: "OCTET STRING 050001"},
+		{name: "bit string", der: []byte{0x03, 0x02, 0x01, 0x86}, want: "BIT STRING (7 bits) 86"},
+		{name: "truncated", der: []byte{0x30, 0x05, 0x01}, err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := certutil.FormatASN1(c.der)
+			if (err != nil) != c.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Fatalf("FormatASN1() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCertificatePolicies(t *testing.T) {
+	type qualifier struct {
+		ID        asn1.ObjectIdentifier
+		Qualifier any
+	}
+	type noticeReference struct {
+		Organization  string `asn1:"utf8"`
+		NoticeNumbers []int
+	}
+	type userNotice struct {
+		NoticeRef    noticeReference
+		ExplicitText asn1.RawValue
+	}
+	type policy struct {
+		ID         asn1.ObjectIdentifier
+		Qualifiers []qualifier `asn1:"optional,omitempty"`
+	}
+
+	// "Zażółć" as a BMPString
+	bmp := asn1.RawValue{Tag: asn1.TagBMPString, Bytes: []byte{0, 'Z', 0, 'a', 0x01, 0x7c, 0, 0xf3, 0x01, 0x42, 0x01, 0x07}}
+	der, err := asn1.Marshal([]policy{
+		{ID: asn1.ObjectIdentifier{2, 23, 140, 1, 1}, Qualifiers: []qualifier{
+			{certutil.OIDCPSQualifier, asn1.RawValue{Tag: asn1.TagIA5String, Bytes: []byte("https://example.com/cps")}},
+			{certutil.OIDUserNoticeQualifier, userNotice{noticeReference{"Example CA", []int{1, 2}}, bmp}},
+		}},
+		{ID: asn1.ObjectIdentifier{2, 5, 29, 32, 0}},
+		{ID: asn1.ObjectIdentifier{1, 2, 3, 4}, Qualifiers: []qualifier{
+			{certutil.OIDUserNoticeQualifier, struct {
+				ExplicitText string `asn1:"utf8"`
+			}{"Internal use only"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("cannot marshal policies: %s", err)
+	}
+
+	tc := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.ExtraExtensions = []pkix.Extension{{Id: certutil.OIDCertificatePoliciesExt, Value: der}}
+	})
+	cert := internal.NewCertificate(tc.leaf)
+
+	want := "Extended Validation\n" +
+		"  CPS: https://example.com/cps\n" +
+		"  User Notice: Zażółć\n" +
+		"  Notice Reference: Example CA #1, #2\n" +
+		"1.2.3.4\n" +
+		"  User Notice: Internal use only"
+	if diff := cmp.Diff(want, cert.CertificatePolicies()); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -75,9 +75,13 @@ func getCertFromTLS(u *url.URL) (*Certificate, error) {
 		return nil, err
 	}
 
-	certs := tlsConn.ConnectionState().PeerCertificates
+	state := tlsConn.ConnectionState()
+	certs := state.PeerCertificates
 	cert := NewCertificate(certs[0])
 	cert.hostname = u.Hostname()
+	cert.servedOverTLS = true
+	cert.ocspStaple = state.OCSPResponse
+	cert.tlsSCTs = state.SignedCertificateTimestamps
 
 	for _, c := range certs[1:] {
 		cert.AddCertificateToChain(NewCertificate(c))
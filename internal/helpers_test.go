@@ -3,15 +3,23 @@ package internal_test
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"io/fs"
+	"math/big"
 	"net/http"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
 )
 
 func loadRawCert(t *testing.T, fs fs.FS, name string) []byte {
@@ -84,3 +92,145 @@ var equateErrorMessage = cmp.Comparer(func(x, y error) bool {
 	}
 	return x.Error() == y.Error()
 })
+
+type testChain struct {
+	root, intermediate, leaf          *x509.Certificate
+	rootKey, intermediateKey, leafKey *ecdsa.PrivateKey
+
+	leafTemplate *x509.Certificate
+}
+
+// newTestChain generates a root, an intermediate and a leaf certificate.
+// The leaf template can be adjusted with modify before signing.
+func newTestChain(t *testing.T, modify func(leaf *x509.Certificate)) *testChain {
+	t.Helper()
+
+	now := time.Now()
+	tc := &testChain{}
+
+	tc.rootKey = newTestKey(t)
+	tc.root = signTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, &tc.rootKey.PublicKey, tc.rootKey)
+
+	tc.intermediateKey = newTestKey(t)
+	tc.intermediate = signTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Test Intermediate"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(60 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, tc.root, &tc.intermediateKey.PublicKey, tc.rootKey)
+
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(90 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if modify != nil {
+		modify(leaf)
+	}
+
+	tc.leafKey = newTestKey(t)
+	tc.leafTemplate = leaf
+	tc.leaf = signTestCert(t, leaf, tc.intermediate, &tc.leafKey.PublicKey, tc.intermediateKey)
+
+	return tc
+}
+
+func (tc *testChain) roots() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(tc.root)
+	return pool
+}
+
+func (tc *testChain) certificate() *internal.Certificate {
+	cert := internal.NewCertificate(tc.leaf)
+	cert.AddCertificateToChain(internal.NewCertificate(tc.intermediate))
+	cert.AddCertificateToChain(internal.NewCertificate(tc.root))
+	return cert
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate key: %s", err)
+	}
+	return key
+}
+
+func signTestCert(t *testing.T, template, parent *x509.Certificate, pub any, priv crypto.Signer) *x509.Certificate {
+	t.Helper()
+
+	if parent == nil {
+		parent = template
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+	if err != nil {
+		t.Fatalf("cannot create certificate: %s", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("cannot parse certificate: %s", err)
+	}
+	return cert
+}
+
+// startTLSServer starts a TLS server presenting the test chain and returns
+// its address. The server only completes handshakes.
+func startTLSServer(t *testing.T, tc *testChain, configure func(*tls.Certificate)) string {
+	t.Helper()
+
+	tlsCert := tls.Certificate{
+		Certificate: [][]byte{tc.leaf.Raw, tc.intermediate.Raw},
+		PrivateKey:  tc.leafKey,
+	}
+	if configure != nil {
+		configure(&tlsCert)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{tlsCert}})
+	if err != nil {
+		t.Fatalf("cannot start TLS server: %s", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func mustOID(t *testing.T, s string) x509.OID {
+	t.Helper()
+
+	oid, err := x509.ParseOID(s)
+	if err != nil {
+		t.Fatalf("cannot parse OID: %s", err)
+	}
+	return oid
+}
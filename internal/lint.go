@@ -0,0 +1,528 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+// LintSeverity defines how serious a lint finding is.
+type LintSeverity int
+
+const (
+	LintNotice LintSeverity = iota
+	LintWarning
+	LintError
+)
+
+func (s LintSeverity) String() string {
+	switch s {
+	case LintError:
+		return "error"
+	case LintWarning:
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// ParseLintSeverity returns a lint severity with a given name.
+func ParseLintSeverity(name string) (LintSeverity, error) {
+	for _, s := range []LintSeverity{LintNotice, LintWarning, LintError} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q", name)
+}
+
+// LintRule defines a check of certificates against a requirement.
+type LintRule struct {
+	Name        string
+	Description string
+	Severity    LintSeverity
+	// Reference points to the requirement, e.g. a section of a standard.
+	Reference string
+	// Applies reports whether the rule applies to the certificate.
+	Applies func(c *Certificate) bool
+	// Check returns descriptions of violations, empty when the certificate
+	// complies with the rule.
+	Check func(c *Certificate) []string
+}
+
+// LintFinding defines a violation of a rule by a certificate.
+type LintFinding struct {
+	Rule        *LintRule
+	Certificate *Certificate
+	Message     string
+}
+
+var lintRules = []*LintRule{}
+
+// RegisterLintRule adds a rule to the registry used by Lint.
+func RegisterLintRule(rule *LintRule) {
+	lintRules = append(lintRules, rule)
+}
+
+// LintRules returns registered lint rules.
+func LintRules() []*LintRule {
+	return slices.Clone(lintRules)
+}
+
+// Lint checks the certificate and its chain against registered rules.
+func (c *Certificate) Lint() []LintFinding {
+	result := []LintFinding{}
+	for _, cert := range append([]*Certificate{c}, c.OrderedChain()...) {
+		for _, rule := range lintRules {
+			if rule.Applies != nil && !rule.Applies(cert) {
+				continue
+			}
+			for _, msg := range rule.Check(cert) {
+				result = append(result, LintFinding{rule, cert, msg})
+			}
+		}
+	}
+	return result
+}
+
+// sharedKeyRule is not registered as it compares certificates of several
+// targets, see SharedKeyFindings.
+var sharedKeyRule = &LintRule{
+	Name:        "weak_key.shared_key",
+	Description: "different certificates must not share a public key or RSA modulus",
+	Severity:    LintError,
+	Reference:   "NIST SP 800-57 Part 1 5.2",
+}
+
+// SharedKeyFindings reports certificates, like leaf certificates of several
+// targets, which are different but share a public key or RSA modulus.
+// Targets describe where each certificate comes from.
+func SharedKeyFindings(targets []string, certs []*Certificate) []LintFinding {
+	result := []LintFinding{}
+	for i, c := range certs {
+		for j, other := range certs {
+			if i == j || c.Equal(other) {
+				continue
+			}
+			if what := sharedKey(c, other); what != "" {
+				msg := fmt.Sprintf("%s of %q served by %s", what, other.CommonName(), targets[j])
+				result = append(result, LintFinding{sharedKeyRule, c, msg})
+			}
+		}
+	}
+	return result
+}
+
+func sharedKey(a, b *Certificate) string {
+	if a.SPKIHash() == b.SPKIHash() {
+		return "shares the public key"
+	}
+
+	keyA, okA := a.cert.PublicKey.(*rsa.PublicKey)
+	keyB, okB := b.cert.PublicKey.(*rsa.PublicKey)
+	if okA && okB && keyA.N.Cmp(keyB.N) == 0 {
+		return "shares the RSA modulus"
+	}
+	return ""
+}
+
+// isTLSServer reports whether the certificate is a TLS server certificate
+// subject to the CA/B Forum Baseline Requirements.
+func isTLSServer(c *Certificate) bool {
+	return !c.cert.IsCA && slices.Contains(c.cert.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+}
+
+// isSMIME reports whether the certificate is a S/MIME certificate subject
+// to the CA/B Forum S/MIME Baseline Requirements.
+func isSMIME(c *Certificate) bool {
+	return !c.cert.IsCA && slices.Contains(c.cert.ExtKeyUsage, x509.ExtKeyUsageEmailProtection)
+}
+
+func isTLSServerOrCA(c *Certificate) bool {
+	return c.cert.IsCA || isTLSServer(c)
+}
+
+func isAnyCertificate(*Certificate) bool {
+	return true
+}
+
+// validityPeriod returns the validity period, which includes both
+// notBefore and notAfter (RFC 5280, section 4.1.2.5).
+func validityPeriod(c *Certificate) time.Duration {
+	return c.cert.NotAfter.Sub(c.cert.NotBefore) + time.Second
+}
+
+// maxTLSValidity returns the maximum validity period of TLS server
+// certificates issued at a given time (TLS BR, section 6.3.2).
+func maxTLSValidity(issued time.Time) int {
+	switch {
+	case issued.Before(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)):
+		return 398
+	case issued.Before(time.Date(2027, 3, 15, 0, 0, 0, 0, time.UTC)):
+		return 200
+	case issued.Before(time.Date(2029, 3, 15, 0, 0, 0, 0, time.UTC)):
+		return 100
+	}
+	return 47
+}
+
+func checkMaxValidity(c *Certificate, days int) []string {
+	if v := validityPeriod(c); v > time.Duration(days)*24*time.Hour {
+		return []string{fmt.Sprintf("validity period of %s exceeds %d days", formatDays(v), days)}
+	}
+	return nil
+}
+
+func hasExtension(c *Certificate, oid fmt.Stringer) bool {
+	for _, e := range c.cert.Extensions {
+		if e.Id.String() == oid.String() {
+			return true
+		}
+	}
+	return false
+}
+
+var allowedTLSCurves = []string{"P-256", "P-384", "P-521"}
+
+func init() {
+	// CA/B Forum Baseline Requirements for TLS server certificates
+	RegisterLintRule(&LintRule{
+		Name:        "tls_br.validity_period",
+		Description: "TLS server certificates must not exceed the maximum validity period",
+		Severity:    LintError,
+		Reference:   "CA/B Forum TLS BR 6.3.2",
+		Applies:     isTLSServer,
+		Check: func(c *Certificate) []string {
+			return checkMaxValidity(c, maxTLSValidity(c.cert.NotBefore))
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "tls_br.san_required",
+		Description: "TLS server certificates must have the Subject Alternative Name extension",
+		Severity:    LintError,
+		Reference:   "CA/B Forum TLS BR 7.1.2.7.12",
+		Applies:     isTLSServer,
+		Check: func(c *Certificate) []string {
+			if !hasExtension(c, certutil.OIDSubjectAltNameExt) {
+				return []string{"Subject Alternative Name extension is missing"}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "tls_br.dns_name_underscore",
+		Description: "DNS names must not contain underscores",
+		Severity:    LintError,
+		Reference:   "CA/B Forum TLS BR 7.1.2.7.12",
+		Applies:     isTLSServer,
+		Check: func(c *Certificate) []string {
+			result := []string{}
+			for _, name := range c.cert.DNSNames {
+				if strings.Contains(name, "_") {
+					result = append(result, fmt.Sprintf("DNS name %q contains an underscore", name))
+				}
+			}
+			return result
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "tls_br.common_name_in_san",
+		Description: "the common name must be one of the Subject Alternative Names",
+		Severity:    LintError,
+		Reference:   "CA/B Forum TLS BR 7.1.4.3",
+		Applies:     isTLSServer,
+		Check: func(c *Certificate) []string {
+			cn := c.cert.Subject.CommonName
+			if cn == "" {
+				return nil
+			}
+			for _, name := range c.cert.DNSNames {
+				if strings.EqualFold(name, cn) {
+					return nil
+				}
+			}
+			for _, ip := range c.cert.IPAddresses {
+				if ip.String() == cn {
+					return nil
+				}
+			}
+			return []string{fmt.Sprintf("common name %q is not in Subject Alternative Names", cn)}
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "tls_br.common_name_discouraged",
+		Description: "the common name is not recommended in subscriber certificates",
+		Severity:    LintNotice,
+		Reference:   "CA/B Forum TLS BR 7.1.2.7.2",
+		Applies:     isTLSServer,
+		Check: func(c *Certificate) []string {
+			if c.cert.Subject.CommonName != "" {
+				return []string{"subject contains a common name"}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "tls_br.key_algorithm",
+		Description: "keys must be RSA of at least 2048 bits or ECDSA on P-256, P-384 or P-521",
+		Severity:    LintError,
+		Reference:   "CA/B Forum TLS BR 6.1.5",
+		Applies:     isTLSServerOrCA,
+		Check: func(c *Certificate) []string {
+			key := c.PublicKey()
+			switch c.cert.PublicKeyAlgorithm {
+			case x509.RSA:
+				if key.Size < 2048 || key.Size%8 != 0 {
+					return []string{fmt.Sprintf("RSA key size of %d bits is not allowed", key.Size)}
+				}
+			case x509.ECDSA:
+				if !slices.Contains(allowedTLSCurves, key.Curve) {
+					return []string{fmt.Sprintf("elliptic curve %s is not allowed", key.Curve)}
+				}
+			default:
+				return []string{fmt.Sprintf("%s keys are not allowed", key.Algorithm)}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "tls_br.aia_ca_issuers",
+		Description: "subscriber certificates must point to the issuing CA certificate",
+		Severity:    LintError,
+		Reference:   "CA/B Forum TLS BR 7.1.2.7.7",
+		Applies:     isTLSServer,
+		Check: func(c *Certificate) []string {
+			if len(c.cert.IssuingCertificateURL) == 0 {
+				return []string{"Authority Information Access has no CA Issuers URL"}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "tls_br.revocation_info",
+		Description: "subscriber certificates should have OCSP or CRL locations",
+		Severity:    LintWarning,
+		Reference:   "CA/B Forum TLS BR 7.1.2.11.2",
+		Applies:     isTLSServer,
+		Check: func(c *Certificate) []string {
+			if len(c.cert.OCSPServer) == 0 && len(c.cert.CRLDistributionPoints) == 0 {
+				return []string{"neither OCSP responder nor CRL distribution point is present"}
+			}
+			return nil
+		},
+	})
+
+	// known weak keys
+	RegisterLintRule(&LintRule{
+		Name:        "weak_key.roca",
+		Description: "RSA keys must not be generated by vulnerable Infineon RSALib",
+		Severity:    LintError,
+		Reference:   "CVE-2017-15361, CA/B Forum TLS BR 6.1.1.3",
+		Applies:     isAnyCertificate,
+		Check: func(c *Certificate) []string {
+			if key, ok := c.cert.PublicKey.(*rsa.PublicKey); ok && certutil.IsROCAVulnerable(key.N) {
+				return []string{"RSA key has the ROCA fingerprint"}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "weak_key.debian",
+		Description: "RSA keys must not be in Debian weak key blocklists",
+		Severity:    LintError,
+		Reference:   "CVE-2008-0166, CA/B Forum TLS BR 6.1.1.3",
+		Applies:     isAnyCertificate,
+		Check: func(c *Certificate) []string {
+			if key, ok := c.cert.PublicKey.(*rsa.PublicKey); ok && certutil.IsDebianWeakKey(key.N) {
+				return []string{"RSA key is in a Debian weak key blocklist"}
+			}
+			return nil
+		},
+	})
+
+	// RFC 5280 structural rules
+	RegisterLintRule(&LintRule{
+		Name:        "rfc5280.serial_number",
+		Description: "serial numbers must be positive and at most 20 octets long",
+		Severity:    LintError,
+		Reference:   "RFC 5280 4.1.2.2",
+		Applies:     isAnyCertificate,
+		Check: func(c *Certificate) []string {
+			serial := c.cert.SerialNumber
+			if serial.Sign() <= 0 {
+				return []string{"serial number is not positive"}
+			}
+			size := len(serial.Bytes())
+			if serial.Bytes()[0]&0x80 != 0 {
+				size++
+			}
+			if size > 20 {
+				return []string{fmt.Sprintf("serial number is %d octets long", size)}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "rfc5280.validity_order",
+		Description: "notAfter must not be earlier than notBefore",
+		Severity:    LintError,
+		Reference:   "RFC 5280 4.1.2.5",
+		Applies:     isAnyCertificate,
+		Check: func(c *Certificate) []string {
+			if c.cert.NotAfter.Before(c.cert.NotBefore) {
+				return []string{"notAfter is earlier than notBefore"}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "rfc5280.empty_subject",
+		Description: "certificates with an empty subject must have a critical Subject Alternative Name extension",
+		Severity:    LintError,
+		Reference:   "RFC 5280 4.2.1.6",
+		Applies:     isAnyCertificate,
+		Check: func(c *Certificate) []string {
+			if len(c.SubjectDN()) > 0 {
+				return nil
+			}
+			if !hasExtension(c, certutil.OIDSubjectAltNameExt) || !c.IsCriticalExtension(certutil.OIDSubjectAltNameExt) {
+				return []string{"subject is empty and Subject Alternative Name is not critical"}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "rfc5280.authority_key_id",
+		Description: "certificates which are not self-issued must have the Authority Key Identifier",
+		Severity:    LintError,
+		Reference:   "RFC 5280 4.2.1.1",
+		Applies:     isAnyCertificate,
+		Check: func(c *Certificate) []string {
+			if !bytes.Equal(c.cert.RawSubject, c.cert.RawIssuer) && len(c.cert.AuthorityKeyId) == 0 {
+				return []string{"Authority Key Identifier is missing"}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "rfc5280.ca_subject_key_id",
+		Description: "CA certificates must have the Subject Key Identifier",
+		Severity:    LintError,
+		Reference:   "RFC 5280 4.2.1.2",
+		Applies:     (*Certificate).IsCA,
+		Check: func(c *Certificate) []string {
+			if len(c.cert.SubjectKeyId) == 0 {
+				return []string{"Subject Key Identifier is missing"}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "rfc5280.ca_key_usage",
+		Description: "CA certificates must have the Key Usage extension with keyCertSign",
+		Severity:    LintError,
+		Reference:   "RFC 5280 4.2.1.3",
+		Applies:     (*Certificate).IsCA,
+		Check: func(c *Certificate) []string {
+			if c.cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+				return []string{"Key Usage does not include keyCertSign"}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "rfc5280.ca_basic_constraints_critical",
+		Description: "Basic Constraints of CA certificates must be critical",
+		Severity:    LintError,
+		Reference:   "RFC 5280 4.2.1.9",
+		Applies:     (*Certificate).IsCA,
+		Check: func(c *Certificate) []string {
+			if !c.IsCriticalExtension(certutil.OIDBasicConstraintsExt) {
+				return []string{"Basic Constraints extension is not critical"}
+			}
+			return nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "rfc5280.key_cert_sign_without_ca",
+		Description: "keyCertSign must only be asserted in CA certificates",
+		Severity:    LintError,
+		Reference:   "RFC 5280 4.2.1.3",
+		Applies:     isAnyCertificate,
+		Check: func(c *Certificate) []string {
+			if !c.cert.IsCA && c.cert.KeyUsage&x509.KeyUsageCertSign != 0 {
+				return []string{"Key Usage includes keyCertSign but the certificate is not a CA"}
+			}
+			return nil
+		},
+	})
+
+	// CA/B Forum Baseline Requirements for S/MIME certificates
+	RegisterLintRule(&LintRule{
+		Name:        "smime_br.validity_period",
+		Description: "S/MIME certificates must not be valid longer than 825 days",
+		Severity:    LintError,
+		Reference:   "CA/B Forum S/MIME BR 6.3.2",
+		Applies:     isSMIME,
+		Check: func(c *Certificate) []string {
+			return checkMaxValidity(c, 825)
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "smime_br.mailbox_address",
+		Description: "S/MIME certificates must have a mailbox address in Subject Alternative Names",
+		Severity:    LintError,
+		Reference:   "CA/B Forum S/MIME BR 7.1.2.3",
+		Applies:     isSMIME,
+		Check: func(c *Certificate) []string {
+			names, err := c.AltNames()
+			if err != nil {
+				return []string{err.Error()}
+			}
+			if len(names.EmailAddresses) > 0 {
+				return nil
+			}
+			for _, n := range names.OtherNames {
+				if n.Type.Equal(certutil.OIDSmtpUTF8Mailbox) {
+					return nil
+				}
+			}
+			return []string{"no rfc822Name or SmtpUTF8Mailbox in Subject Alternative Names"}
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "smime_br.ext_key_usage",
+		Description: "S/MIME certificates must not include serverAuth, codeSigning, timeStamping or anyExtendedKeyUsage",
+		Severity:    LintError,
+		Reference:   "CA/B Forum S/MIME BR 7.1.2.3",
+		Applies:     isSMIME,
+		Check: func(c *Certificate) []string {
+			result := []string{}
+			for _, eku := range []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageCodeSigning, x509.ExtKeyUsageTimeStamping, x509.ExtKeyUsageAny} {
+				if slices.Contains(c.cert.ExtKeyUsage, eku) {
+					result = append(result, fmt.Sprintf("Extended Key Usage includes %s", eku))
+				}
+			}
+			return result
+		},
+	})
+	RegisterLintRule(&LintRule{
+		Name:        "smime_br.rsa_key_size",
+		Description: "RSA keys must be at least 2048 bits long",
+		Severity:    LintError,
+		Reference:   "CA/B Forum S/MIME BR 6.1.5",
+		Applies:     isSMIME,
+		Check: func(c *Certificate) []string {
+			if key := c.PublicKey(); c.cert.PublicKeyAlgorithm == x509.RSA && key.Size < 2048 {
+				return []string{fmt.Sprintf("RSA key size of %d bits is not allowed", key.Size)}
+			}
+			return nil
+		},
+	})
+}
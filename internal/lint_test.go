@@ -0,0 +1,126 @@
+package internal_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(*x509.Certificate)
+		want   []string
+	}{
+		{
+			name: "compliant",
+			modify: func(c *x509.Certificate) {
+				c.Subject = pkix.Name{}
+				c.IssuingCertificateURL = []string{"http://ca.example/int.crt"}
+				c.CRLDistributionPoints = []string{"http://ca.example/int.crl"}
+			},
+			want: []string{},
+		},
+		{
+			name: "defaults",
+			want: []string{
+				"example.com: tls_br.common_name_discouraged (notice)",
+				"example.com: tls_br.aia_ca_issuers (error)",
+				"example.com: tls_br.revocation_info (warning)",
+			},
+		},
+		{
+			name: "server",
+			modify: func(c *x509.Certificate) {
+				c.NotAfter = c.NotBefore.Add(400 * 24 * time.Hour)
+				c.DNSNames = []string{"under_score.example.com"}
+				c.KeyUsage |= x509.KeyUsageCertSign
+				c.IssuingCertificateURL = []string{"http://ca.example/int.crt"}
+				c.OCSPServer = []string{"http://ocsp.example"}
+			},
+			want: []string{
+				"example.com: tls_br.validity_period (error)",
+				"example.com: tls_br.dns_name_underscore (error)",
+				"example.com: tls_br.common_name_in_san (error)",
+				"example.com: tls_br.common_name_discouraged (notice)",
+				"example.com: rfc5280.key_cert_sign_without_ca (error)",
+			},
+		},
+		{
+			name: "email",
+			modify: func(c *x509.Certificate) {
+				c.Subject = pkix.Name{}
+				c.DNSNames = nil
+				c.EmailAddresses = []string{"jan@example.com"}
+				c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection, x509.ExtKeyUsageServerAuth}
+				c.IssuingCertificateURL = []string{"http://ca.example/int.crt"}
+				c.OCSPServer = []string{"http://ocsp.example"}
+			},
+			want: []string{
+				"<empty>: smime_br.ext_key_usage (error)",
+			},
+		},
+		{
+			name: "email with code signing",
+			modify: func(c *x509.Certificate) {
+				c.Subject = pkix.Name{}
+				c.DNSNames = nil
+				c.EmailAddresses = []string{"jan@example.com"}
+				c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection, x509.ExtKeyUsageCodeSigning, x509.ExtKeyUsageTimeStamping}
+				c.IssuingCertificateURL = []string{"http://ca.example/int.crt"}
+				c.OCSPServer = []string{"http://ocsp.example"}
+			},
+			want: []string{
+				"<empty>: smime_br.ext_key_usage (error)",
+				"<empty>: smime_br.ext_key_usage (error)",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cert := newTestChain(t, tc.modify).certificate()
+
+			got := []string{}
+			for _, f := range cert.Lint() {
+				name := f.Certificate.CommonName()
+				if name == "" {
+					name = "<empty>"
+				}
+				got = append(got, name+": "+f.Rule.Name+" ("+f.Rule.Severity.String()+")")
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	names := map[string]bool{}
+	for _, r := range internal.LintRules() {
+		if names[r.Name] {
+			t.Errorf("duplicate rule %q", r.Name)
+		}
+		names[r.Name] = true
+
+		if r.Reference == "" || r.Check == nil {
+			t.Errorf("rule %q has no reference or check", r.Name)
+		}
+	}
+}
+
+func TestParseLintSeverity(t *testing.T) {
+	if _, err := internal.ParseLintSeverity("fatal"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if s, err := internal.ParseLintSeverity("warning"); err != nil || s != internal.LintWarning {
+		t.Fatalf("ParseLintSeverity() = %v, %v", s, err)
+	}
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const pinPrefix = "sha256/"
+
+// ParsePin parses a public key pin in "sha256/<base64>" form and returns
+// the base64-encoded SPKI hash.
+func ParsePin(pin string) (string, error) {
+	if !strings.HasPrefix(pin, pinPrefix) {
+		return "", fmt.Errorf("pin %q: unsupported hash, only sha256 is supported", pin)
+	}
+
+	hash := strings.TrimPrefix(pin, pinPrefix)
+	raw, err := base64.StdEncoding.DecodeString(hash)
+	if err != nil || len(raw) != sha256.Size {
+		return "", fmt.Errorf("pin %q: invalid SHA-256 hash", pin)
+	}
+
+	return hash, nil
+}
+
+// ParsePinFile parses a file with one pin per line. Empty lines and lines
+// starting with "#" are ignored.
+func ParsePinFile(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pins := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pin, err := ParsePin(line)
+		if err != nil {
+			return nil, err
+		}
+		pins = append(pins, pin)
+	}
+
+	return pins, nil
+}
+
+// MatchPins returns the certificate and certificates of its chain whose
+// SPKI hash matches any of the pins. Certificates downloaded from Authority
+// Information Access URLs are skipped, as clients match only served ones.
+func (c *Certificate) MatchPins(pins []string) []*Certificate {
+	result := []*Certificate{}
+	for _, cert := range append([]*Certificate{c}, c.OrderedChain()...) {
+		if !cert.fromAIA && cert.IsPinned(pins) {
+			result = append(result, cert)
+		}
+	}
+	return result
+}
+
+// IsPinned reports whether the SPKI hash of the certificate matches any of the pins.
+func (c *Certificate) IsPinned(pins []string) bool {
+	hash := c.SPKIHash()
+	for _, pin := range pins {
+		if pin == hash {
+			return true
+		}
+	}
+	return false
+}
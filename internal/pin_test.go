@@ -0,0 +1,94 @@
+package internal_test
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+)
+
+func TestParsePin(t *testing.T) {
+	cases := []struct {
+		pin  string
+		want string
+		err  error
+	}{
+		{pin: "sha256/EeIEKwf6YIadUiMKYxtLghdV6qqAbNW/5W3TT3yInIo=", want: "EeIEKwf6YIadUiMKYxtLghdV6qqAbNW/5W3TT3yInIo="},
+		{pin: "sha1/EeIEKwf6YIadUiMKYxtLghdV6qq=", err: fmt.Errorf(`pin "sha1/EeIEKwf6YIadUiMKYxtLghdV6qq=": unsupported hash, only sha256 is supported`)},
+		{pin: "sha256/Zm9v", err: fmt.Errorf(`pin "sha256/Zm9v": invalid SHA-256 hash`)},
+		{pin: "sha256/!!!", err: fmt.Errorf(`pin "sha256/!!!": invalid SHA-256 hash`)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.pin, func(t *testing.T) {
+			got, err := internal.ParsePin(c.pin)
+			if diff := cmp.Diff(c.err, err, equateErrorMessage); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+			if got != c.want {
+				t.Fatalf("ParsePin() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestMatchPins(t *testing.T) {
+	tc := newTestChain(t, nil)
+	cert := tc.certificate()
+
+	intermediate := internal.NewCertificate(tc.intermediate)
+	root := internal.NewCertificate(tc.root)
+	other := newTestChain(t, nil).certificate()
+
+	path := filepath.Join(t.TempDir(), "pins.txt")
+	content := fmt.Sprintf("# backup pin\nsha256/%s\n\nsha256/%s\n", other.SPKIHash(), intermediate.SPKIHash())
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("cannot write pin file: %s", err)
+	}
+
+	pins, err := internal.ParsePinFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if diff := cmp.Diff([]*internal.Certificate{intermediate}, cert.MatchPins(pins)); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if matched := cert.MatchPins([]string{other.SPKIHash()}); len(matched) != 0 {
+		t.Fatalf("expected no matches, got %d", len(matched))
+	}
+
+	if diff := cmp.Diff([]*internal.Certificate{intermediate, root}, cert.OrderedChain()); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMatchPins_AIA(t *testing.T) {
+	var tc *testChain
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(tc.intermediate.Raw)
+	}))
+	t.Cleanup(srv.Close)
+
+	tc = newTestChain(t, func(c *x509.Certificate) {
+		c.IssuingCertificateURL = []string{srv.URL + "/int.der"}
+	})
+	intermediate := internal.NewCertificate(tc.intermediate)
+
+	cert := internal.NewCertificate(tc.leaf)
+	cert.DownloadIssuingCertificate()
+	if diff := cmp.Diff([]*internal.Certificate{intermediate}, cert.OrderedChain()); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if matched := cert.MatchPins([]string{intermediate.SPKIHash()}); len(matched) != 0 {
+		t.Fatalf("expected no matches, got %d", len(matched))
+	}
+}
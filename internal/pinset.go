@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// hpkpMaxAge defines max-age of generated Public-Key-Pins headers.
+const hpkpMaxAge = 60 * 24 * time.Hour
+
+// PinSetFormats lists supported pin set formats.
+var PinSetFormats = []string{"android", "ios", "okhttp", "hpkp"}
+
+// Pin defines a public key pin.
+type Pin struct {
+	// Hash is base64-encoded SHA-256 hash of the subject public key info.
+	Hash string
+	// Comment describes the pinned key.
+	Comment string
+	// Leaf reports whether the pin refers to an end-entity key.
+	Leaf bool
+}
+
+// PinSet defines public key pins for a domain.
+type PinSet struct {
+	Domain     string
+	Pins       []Pin
+	Expiration time.Time
+}
+
+// PinSet returns pins of the certificate and its chain, followed by
+// given backup pins (base64-encoded SPKI hashes).
+func (c *Certificate) PinSet(backup []string) *PinSet {
+	ps := &PinSet{Domain: c.hostname, Expiration: c.NotAfter()}
+	if ps.Domain == "" {
+		ps.Domain = c.CommonName()
+		if names := c.DNSNames(); len(names) > 0 {
+			ps.Domain = names[0]
+		}
+	}
+
+	ps.Pins = append(ps.Pins, Pin{c.SPKIHash(), fmt.Sprintf("%s (leaf)", c.CommonName()), true})
+	for _, chainCert := range c.OrderedChain() {
+		role := "intermediate"
+		if chainCert.Subject().String() == chainCert.Issuer().String() {
+			role = "root"
+		}
+		ps.Pins = append(ps.Pins, Pin{chainCert.SPKIHash(), fmt.Sprintf("%s (%s)", chainCert.CommonName(), role), false})
+	}
+
+	for _, hash := range backup {
+		ps.Pins = append(ps.Pins, Pin{hash, "backup", false})
+	}
+
+	return ps
+}
+
+// Format returns the pin set in a given format.
+func (ps *PinSet) Format(format string) (string, error) {
+	switch format {
+	case "android":
+		return ps.Android(), nil
+	case "ios":
+		return ps.IOS(), nil
+	case "okhttp":
+		return ps.OkHttp(), nil
+	case "hpkp":
+		return ps.HPKP(), nil
+	}
+	return "", fmt.Errorf("unknown pin set format %q", format)
+}
+
+// Android returns a domain-config for Android network_security_config.xml.
+func (ps *PinSet) Android() string {
+	b := strings.Builder{}
+	b.WriteString("<domain-config>\n")
+	b.WriteString(fmt.Sprintf("    <domain includeSubdomains=\"false\">%s</domain>\n", html.EscapeString(ps.Domain)))
+	b.WriteString(fmt.Sprintf("    <pin-set expiration=\"%s\">\n", ps.Expiration.UTC().Format(time.DateOnly)))
+	for _, pin := range ps.Pins {
+		b.WriteString(fmt.Sprintf("        <!-- %s -->\n", xmlComment(pin.Comment)))
+		b.WriteString(fmt.Sprintf("        <pin digest=\"SHA-256\">%s</pin>\n", pin.Hash))
+	}
+	b.WriteString("    </pin-set>\n")
+	b.WriteString("</domain-config>")
+	return b.String()
+}
+
+// IOS returns a NSPinnedDomains fragment for Info.plist.
+func (ps *PinSet) IOS() string {
+	b := strings.Builder{}
+	b.WriteString("<key>NSPinnedDomains</key>\n")
+	b.WriteString("<dict>\n")
+	b.WriteString(fmt.Sprintf("    <key>%s</key>\n", html.EscapeString(ps.Domain)))
+	b.WriteString("    <dict>\n")
+	b.WriteString("        <key>NSIncludesSubdomains</key>\n")
+	b.WriteString("        <false/>\n")
+
+	for _, leaf := range []bool{true, false} {
+		key := "NSPinnedCAIdentities"
+		if leaf {
+			key = "NSPinnedLeafIdentities"
+		}
+
+		b.WriteString(fmt.Sprintf("        <key>%s</key>\n", key))
+		b.WriteString("        <array>\n")
+		for _, pin := range ps.Pins {
+			if pin.Leaf != leaf {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("            <!-- %s -->\n", xmlComment(pin.Comment)))
+			b.WriteString("            <dict>\n")
+			b.WriteString("                <key>SPKI-SHA256-BASE64</key>\n")
+			b.WriteString(fmt.Sprintf("                <string>%s</string>\n", pin.Hash))
+			b.WriteString("            </dict>\n")
+		}
+		b.WriteString("        </array>\n")
+	}
+
+	b.WriteString("    </dict>\n")
+	b.WriteString("</dict>")
+	return b.String()
+}
+
+// xmlComment escapes text placed in an XML comment, which must not
+// contain "--".
+func xmlComment(s string) string {
+	s = html.EscapeString(s)
+	for strings.Contains(s, "--") {
+		s = strings.ReplaceAll(s, "--", "- -")
+	}
+	return s
+}
+
+// OkHttp returns Kotlin code building an OkHttp CertificatePinner.
+func (ps *PinSet) OkHttp() string {
+	b := strings.Builder{}
+	b.WriteString("val certificatePinner = CertificatePinner.Builder()\n")
+	for _, pin := range ps.Pins {
+		b.WriteString(fmt.Sprintf("    .add(%q, \"sha256/%s\") // %s\n", ps.Domain, pin.Hash, pin.Comment))
+	}
+	b.WriteString("    .build()")
+	return b.String()
+}
+
+// HPKP returns a legacy Public-Key-Pins header.
+func (ps *PinSet) HPKP() string {
+	parts := []string{}
+	for _, pin := range ps.Pins {
+		parts = append(parts, fmt.Sprintf("pin-sha256=%q", pin.Hash))
+	}
+	parts = append(parts, fmt.Sprintf("max-age=%d", int(hpkpMaxAge.Seconds())))
+
+	return "Public-Key-Pins: " + strings.Join(parts, "; ")
+}
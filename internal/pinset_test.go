@@ -0,0 +1,98 @@
+package internal_test
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+)
+
+func TestPinSet(t *testing.T) {
+	tc := newTestChain(t, nil)
+	cert := tc.certificate()
+	leaf := cert.SPKIHash()
+	intermediate := internal.NewCertificate(tc.intermediate).SPKIHash()
+	root := internal.NewCertificate(tc.root).SPKIHash()
+	backup := newTestChain(t, nil).certificate().SPKIHash()
+
+	ps := cert.PinSet([]string{backup})
+
+	want := []internal.Pin{
+		{Hash: leaf, Comment: "example.com (leaf)", Leaf: true},
+		{Hash: intermediate, Comment: "Test Intermediate (intermediate)"},
+		{Hash: root, Comment: "Test Root (root)"},
+		{Hash: backup, Comment: "backup"},
+	}
+	if diff := cmp.Diff(want, ps.Pins); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+	if ps.Domain != "example.com" {
+		t.Fatalf("Domain = %q, want %q", ps.Domain, "example.com")
+	}
+
+	wantHPKP := fmt.Sprintf(`Public-Key-Pins: pin-sha256="%s"; pin-sha256="%s"; pin-sha256="%s"; pin-sha256="%s"; max-age=5184000`, leaf, intermediate, root, backup)
+	if got := ps.HPKP(); got != wantHPKP {
+		t.Fatalf("HPKP() = %q, want %q", got, wantHPKP)
+	}
+
+	cases := []struct {
+		format string
+		want   []string
+	}{
+		{format: "android", want: []string{
+			`<domain includeSubdomains="false">example.com</domain>`,
+			fmt.Sprintf(`<pin-set expiration="%s">`, tc.leaf.NotAfter.UTC().Format("2006-01-02")),
+			fmt.Sprintf(`<pin digest="SHA-256">%s</pin>`, root),
+		}},
+		{format: "ios", want: []string{
+			"<key>example.com</key>",
+			fmt.Sprintf("<key>NSPinnedLeafIdentities</key>\n        <array>\n            <!-- example.com (leaf) -->\n            <dict>\n                <key>SPKI-SHA256-BASE64</key>\n                <string>%s</string>", leaf),
+			fmt.Sprintf("<key>NSPinnedCAIdentities</key>\n        <array>\n            <!-- Test Intermediate (intermediate) -->\n            <dict>\n                <key>SPKI-SHA256-BASE64</key>\n                <string>%s</string>", intermediate),
+		}},
+		{format: "okhttp", want: []string{
+			fmt.Sprintf(`.add("example.com", "sha256/%s") // backup`, backup),
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			got, err := ps.Format(c.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for _, w := range c.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("output does not contain %q:\n%s", w, got)
+				}
+			}
+		})
+	}
+
+	// backup pins are not end-entity keys of the served chain
+	if ios := ps.IOS(); strings.Index(ios, "<!-- backup -->") < strings.Index(ios, "NSPinnedCAIdentities") {
+		t.Errorf("backup pin not listed under NSPinnedCAIdentities:\n%s", ios)
+	}
+
+	if _, err := ps.Format("hsts"); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+
+	t.Run("comment with double hyphen", func(t *testing.T) {
+		cert := newTestChain(t, func(leaf *x509.Certificate) {
+			leaf.Subject.CommonName = "a--b---c"
+		}).certificate()
+
+		for _, format := range []string{"android", "ios"} {
+			got, err := cert.PinSet(nil).Format(format)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !strings.Contains(got, "<!-- a- -b- - -c (leaf) -->") {
+				t.Errorf("%s: output does not contain escaped comment:\n%s", format, got)
+			}
+		}
+	})
+}
@@ -1,13 +1,17 @@
 package internal
 
 import (
-	"crypto/x509/pkix"
+	"crypto"
+	"encoding/asn1"
+	"encoding/hex"
 	"fmt"
 	"math/big"
+	"net"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	ct "github.com/google/certificate-transparency-go"
 	"github.com/gosuri/uitable"
 	"github.com/krzysdabro/tlscert/internal/certutil"
 )
@@ -15,78 +19,238 @@ import (
 var (
 	tableSeparator = color.New(color.FgHiBlack).Sprint(" │ ")
 
-	redBadge   = color.New(color.BgHiRed, color.FgWhite)
-	greenBadge = color.New(color.BgHiGreen, color.FgBlack)
+	redBadge    = color.New(color.BgHiRed, color.FgWhite)
+	greenBadge  = color.New(color.BgHiGreen, color.FgBlack)
+	yellowBadge = color.New(color.BgHiYellow, color.FgBlack)
+
+	warningText = color.New(color.FgHiYellow)
+	redText     = color.New(color.FgHiRed)
+	greenText   = color.New(color.FgHiGreen)
 )
 
 // PrintOptions defines cetificate printing options.
 type PrintOptions struct {
-	SCTs bool
+	VerifyOptions
+
+	SCTs       bool
+	Revocation RevocationMode
+
+	// CTInclusion enables verification of SCT inclusion proofs.
+	CTInclusion bool
+	// CTLogURL overrides the URL of the log with CTLogID used to verify inclusion proofs.
+	CTLogURL string
+	CTLogID  ct.SHA256Hash
+	// CTLogKey is the DER-encoded public key of the log with CTLogID, required
+	// when the log is not in the log list.
+	CTLogKey []byte
+
+	// Pins lists base64-encoded SPKI hashes of pinned public keys.
+	Pins []string
+
+	// Precertificate is paired with the printed certificate to show which SCTs correspond to it.
+	Precertificate *Certificate
+
+	// DNFormat defines how subject, issuer and directory names are printed.
+	DNFormat DNFormat
+
+	// TrustedLists are used to check whether qualified certificates were issued by qualified trust services.
+	TrustedLists []*certutil.TrustedList
 }
 
 // Print prints details about certificate.
 func (c *Certificate) Print(opts *PrintOptions) {
-	fmt.Printf("%s %s\n", certStatus(c), c.CommonName())
+	revocation := c.RevocationStatus(opts.Revocation, opts.currentTime())
+	fmt.Printf("%s %s\n", certStatus(c, revocation, &opts.VerifyOptions), c.CommonName())
+
+	precert := opts.Precertificate
+	if precert != nil && (c.IsCA() || c.IsPrecertificate()) {
+		precert = nil
+	}
 
 	table := uitable.New()
 	table.Wrap = true
 	table.Separator = tableSeparator
 
-	table.AddRow("Subject", printPkixName(c.Subject()))
-	table.AddRow("Issuer", printPkixName(c.Issuer()))
+	if c.IsPrecertificate() {
+		table.AddRow("Precertificate", warningText.Sprint("CT poison extension present, not usable for TLS"))
+	}
+	if precert != nil {
+		table.AddRow("Precertificate", formatPrecertificateMatch(c.MatchPrecertificate(precert)))
+	}
+
+	table.AddRow("Subject", opts.DNFormat.Format(c.SubjectDN()))
+	table.AddRow("Issuer", opts.DNFormat.Format(c.IssuerDN()))
 	table.AddRow("Signature Algorithm", c.SignatureAlgorithm())
+	table.AddRow("Public Key", formatPublicKey(c.PublicKey()))
 	if v := c.KeyUsage(); v != "" {
-		table.AddRow("Key Usage", v)
+		addExtensionRow(table, c, certutil.OIDKeyUsageExt, "Key Usage", v)
 	}
 	if v := c.ExtKeyUsage(); v != "" {
-		table.AddRow("Extended Key Usage", v)
+		addExtensionRow(table, c, certutil.OIDExtKeyUsageExt, "Extended Key Usage", v)
+	}
+	if v := c.BasicConstraints(); v != "" {
+		addExtensionRow(table, c, certutil.OIDBasicConstraintsExt, "Basic Constraints", v)
+	}
+	if v := c.NameConstraints(opts.DNFormat); v != "" {
+		addExtensionRow(table, c, certutil.OIDNameConstraintsExt, "Name Constraints", v)
 	}
 	if v := c.CertificatePolicies(); v != "" {
-		table.AddRow("Certificate Policies", v)
+		addExtensionRow(table, c, certutil.OIDCertificatePoliciesExt, "Certificate Policies", v)
+	}
+	if v := c.PolicyMappings(); v != "" {
+		addExtensionRow(table, c, certutil.OIDPolicyMappingsExt, "Policy Mappings", v)
+	}
+	if v := c.PolicyConstraints(); v != "" {
+		addExtensionRow(table, c, certutil.OIDPolicyConstraintsExt, "Policy Constraints", v)
+	}
+	if v := c.InhibitAnyPolicy(); v >= 0 {
+		addExtensionRow(table, c, certutil.OIDInhibitAnyPolicyExt, "Inhibit anyPolicy", fmt.Sprintf("Skip Certs: %d", v))
+	}
+	if v := c.QCStatement(opts.DNFormat); len(v) > 0 {
+		addExtensionRow(table, c, certutil.OIDQCStatementsExt, "QC Statement", v)
 	}
-	if v := c.QCStatement(); len(v) > 0 {
-		table.AddRow("QC Statement", v)
+	if v := c.TLSFeatures(); v != "" {
+		addExtensionRow(table, c, certutil.OIDTLSFeatureExt, "TLS Feature", v)
+	}
+	if v := c.SubjectDirectoryAttributes(); v != "" {
+		addExtensionRow(table, c, certutil.OIDSubjectDirectoryAttributesExt, "Subject Directory Attributes", v)
+	}
+	if len(opts.TrustedLists) > 0 && !c.IsCA() {
+		table.AddRow("Qualified", formatQualifiedStatus(c.QualifiedStatus(opts.TrustedLists)))
+	}
+	if opts.Purpose != "" {
+		table.AddRow("Purpose", formatPurpose(c, opts.Purpose))
 	}
 	table.AddRow("Not Valid Before", c.NotBefore().Local().String())
-	table.AddRow("Not Valid After", c.NotAfter().Local().String())
+	table.AddRow("Not Valid After", c.NotAfter().Local().String()+expiryWarning(c, opts.currentTime()))
 
-	if certDNS := c.DNSNames(); len(certDNS) > 0 {
-		table.AddRow("DNS Names", strings.Join(certDNS, "\n"))
+	addAltNameRows(table, c, opts.DNFormat)
+	if names, err := c.IssuerAltNames(); err != nil {
+		addExtensionRow(table, c, certutil.OIDIssuerAltNameExt, "Issuer Alternative Names", warningText.Sprint(err.Error()))
+	} else if v := formatGeneralNames(names, opts.DNFormat); len(v) > 0 {
+		addExtensionRow(table, c, certutil.OIDIssuerAltNameExt, "Issuer Alternative Names", strings.Join(v, "\n"))
 	}
 
-	if certIPs := c.IPAddresses(); len(certIPs) > 0 {
-		ips := make([]string, len(certIPs))
-		for i, ip := range certIPs {
-			ips[i] = ip.String()
+	table.AddRow("Serial Number", formatBigInt(c.SerialNumber()))
+	table.AddRow("SHA-256 Fingerprint", formatBytes(c.Fingerprint(crypto.SHA256)))
+	table.AddRow("SHA-1 Fingerprint", formatBytes(c.Fingerprint(crypto.SHA1)))
+	if c.IsPinned(opts.Pins) {
+		table.AddRow("SPKI SHA-256", c.SPKIHash()+greenText.Sprint(" (pinned)"))
+	} else {
+		table.AddRow("SPKI SHA-256", c.SPKIHash())
+	}
+	if v := c.SubjectKeyID(); len(v) > 0 {
+		addExtensionRow(table, c, certutil.OIDSubjectKeyIDExt, "Subject Key ID", formatBytes(v))
+	}
+	if v := c.AuthorityKeyID(); len(v) > 0 {
+		addExtensionRow(table, c, certutil.OIDAuthorityKeyIDExt, "Authority Key ID", formatBytes(v))
+	}
+	if v := c.CRLDistributionPoints(); len(v) > 0 {
+		addExtensionRow(table, c, certutil.OIDCRLDistributionPointsExt, "CRL Distribution Points", strings.Join(v, "\n"))
+	}
+	if names, err := c.FreshestCRL(); err != nil {
+		addExtensionRow(table, c, certutil.OIDFreshestCRLExt, "Freshest CRL", warningText.Sprint(err.Error()))
+	} else if v := formatGeneralNames(names, opts.DNFormat); len(v) > 0 {
+		addExtensionRow(table, c, certutil.OIDFreshestCRLExt, "Freshest CRL", strings.Join(v, "\n"))
+	}
+	if v := c.AuthorityInfoAccess(); v != "" {
+		addExtensionRow(table, c, certutil.OIDAuthorityInfoAccessExt, "Authority Info Access", v)
+	}
+	if v, err := c.SubjectInfoAccess(); err != nil {
+		addExtensionRow(table, c, certutil.OIDSubjectInfoAccessExt, "Subject Info Access", warningText.Sprint(err.Error()))
+	} else if len(v) > 0 {
+		addExtensionRow(table, c, certutil.OIDSubjectInfoAccessExt, "Subject Info Access", formatSubjectInfoAccess(v, opts.DNFormat))
+	}
+	for _, e := range c.UnknownExtensions() {
+		addExtensionRow(table, c, e.Id, certutil.OIDNameOrString(e.Id), formatASN1(e.Value))
+	}
+
+	for _, r := range revocation.OCSP {
+		table.AddRow("OCSP", formatOCSPStatus(r, nil))
+	}
+	if revocation.OCSPErr != nil {
+		table.AddRow("OCSP", formatOCSPStatus(nil, revocation.OCSPErr))
+	}
+
+	switch {
+	case revocation.MissingStaple:
+		table.AddRow("OCSP Staple", redText.Sprint("Missing, but the certificate requires OCSP stapling (Must-Staple)"))
+	case revocation.StapleErr != nil:
+		table.AddRow("OCSP Staple", warningText.Sprint(revocation.StapleErr.Error()))
+	case revocation.Staple != nil:
+		v := formatOCSPStatus(revocation.Staple, nil)
+		if mismatches := revocation.StapleMismatches(); len(mismatches) > 0 {
+			for _, r := range mismatches {
+				v += redText.Sprintf("\nDiffers from responder %s (%s)", r.URL, r.StatusString())
+			}
+		} else if len(revocation.OCSP) > 0 {
+			v += "\nMatches responders"
 		}
-		table.AddRow("IP Addresses", ips)
+		table.AddRow("OCSP Staple", v)
 	}
 
-	table.AddRow("Serial Number", formatBigInt(c.SerialNumber()))
+	if revocation.CRLChecked {
+		table.AddRow("CRL", formatCRLStatus(revocation.CRL, revocation.CRLErr))
+	}
+
+	// publicly trusted certificates always carry SCTs, so the compliance
+	// check is skipped for ones without any (e.g. issued by a private CA)
+	sctList := c.SignedCertificateTimestamps()
+	if opts.SCTs && len(sctList) > 0 && !c.IsCA() && !c.IsPrecertificate() {
+		table.AddRow("CT Compliance", formatCTCompliance(c.CTCompliance(opts.currentTime())))
+	}
 
-	if sctList := c.SignedCertificateTimestamps(); opts.SCTs && len(sctList) > 0 {
+	if opts.SCTs && len(sctList) > 0 {
 		for i, sct := range sctList {
 			logOperator := "Unknown"
-			if log := certutil.GetSCTLog(sct); log != nil {
+			if log := certutil.GetSCTLog(sct.SignedCertificateTimestamp); log != nil {
 				logOperator = log.Description
 			}
 
+			verification, err := c.VerifySCT(sct)
+			verificationText := verification.String()
+			if err != nil {
+				verificationText = fmt.Sprintf("%s (%s)", verificationText, err)
+			}
+			switch verification {
+			case certutil.SCTVerified:
+			case certutil.SCTInvalid:
+				verificationText = redText.Sprint(verificationText)
+			default:
+				verificationText = warningText.Sprint(verificationText)
+			}
+
 			logKeyID := big.NewInt(0)
 			logKeyID.SetBytes(sct.LogID.KeyID[:])
 
 			encodedSignature := big.NewInt(0)
 			encodedSignature.SetBytes(sct.Signature.Signature)
 
+			if precert != nil {
+				status, _ := c.VerifyPrecertificateSCT(precert, sct)
+				verificationText += "\nPrecertificate: " + formatPrecertificateSCT(status)
+			}
+
+			if opts.CTInclusion {
+				logURL, logKey := "", []byte(nil)
+				if sct.LogID.KeyID == opts.CTLogID {
+					logURL, logKey = opts.CTLogURL, opts.CTLogKey
+				}
+				verificationText += "\nInclusion: " + formatInclusionProof(c.VerifySCTInclusion(sct, logURL, logKey))
+			}
+
 			table.AddRow(
 				fmt.Sprintf("SCT #%d", i+1),
 				fmt.Sprintf(
-					"Version: %s\nLog Operator and Key ID:\n%s\n%s\nTimestamp: %s\nSignature Algorithm: %s\nSignature:\n%s",
+					"Version: %s\nDelivery: %s\nLog Operator and Key ID:\n%s\n%s\nTimestamp: %s\nSignature Algorithm: %s\nSignature:\n%s\nVerification: %s",
 					sct.SCTVersion.String(),
+					sct.Source.String(),
 					indentText(logOperator, 1),
 					indentText(formatBigInt(logKeyID), 1),
 					time.Unix(int64(sct.Timestamp/1000), 0).Local().String(),
 					sct.Signature.Algorithm.Signature.String(),
 					indentText(formatBigInt(encodedSignature), 1),
+					verificationText,
 				),
 			)
 		}
@@ -95,44 +259,204 @@ func (c *Certificate) Print(opts *PrintOptions) {
 	fmt.Println(table)
 }
 
-// modified version of
-// https://github.com/golang/go/blob/6db72bb92b2ab681ae177589b70b573e6e337b96/src/crypto/x509/pkix/pkix.go#L27-L36
-var attributeTypeNames = map[string]string{
-	"2.5.4.6":                  "C",
-	"2.5.4.10":                 "O",
-	"2.5.4.11":                 "OU",
-	"2.5.4.3":                  "CN",
-	"2.5.4.5":                  "SERIALNUMBER",
-	"2.5.4.7":                  "L",
-	"2.5.4.8":                  "ST",
-	"2.5.4.9":                  "STREET",
-	"2.5.4.15":                 "businessCategory",
-	"2.5.4.17":                 "POSTALCODE",
-	"2.5.4.97":                 "organizationIdentifier",
-	"1.3.6.1.4.1.311.60.2.1.1": "jurisdictionOfIncorporationLocalityName",
-	"1.3.6.1.4.1.311.60.2.1.2": "jurisdictionOfIncorporationStateOrProvinceName",
-	"1.3.6.1.4.1.311.60.2.1.3": "jurisdictionOfIncorporationCountryName",
-}
-
-func printPkixName(name pkix.Name) string {
-	b := strings.Builder{}
+// addAltNameRows adds rows for each type of names in the Subject Alternative Name extension.
+func addAltNameRows(table *uitable.Table, c *Certificate, dnFormat DNFormat) {
+	names, err := c.AltNames()
+	if err != nil {
+		addExtensionRow(table, c, certutil.OIDSubjectAltNameExt, "Alternative Names", warningText.Sprint(err.Error()))
+		return
+	}
+
+	for _, row := range altNameRows(names, dnFormat) {
+		if len(row.values) > 0 {
+			addExtensionRow(table, c, certutil.OIDSubjectAltNameExt, row.label, strings.Join(row.values, "\n"))
+		}
+	}
+}
+
+// formatGeneralNames returns names prefixed with their type.
+func formatGeneralNames(names *certutil.AltNames, dnFormat DNFormat) []string {
+	result := []string{}
+	for _, row := range altNameRows(names, dnFormat) {
+		for _, v := range row.values {
+			result = append(result, fmt.Sprintf("%s: %s", row.prefix, v))
+		}
+	}
+	return result
+}
+
+// formatSubjectInfoAccess returns access methods followed by their locations.
+func formatSubjectInfoAccess(descriptions []certutil.AccessDescription, dnFormat DNFormat) string {
+	result := []string{}
+	for _, ad := range descriptions {
+		result = append(result, fmt.Sprintf("%s: %s", certutil.OIDNameOrString(ad.Method), strings.Join(formatGeneralNames(ad.Location, dnFormat), ", ")))
+	}
+	return strings.Join(result, "\n")
+}
+
+type altNameRow struct {
+	label  string
+	prefix string
+	values []string
+}
+
+func altNameRows(names *certutil.AltNames, dnFormat DNFormat) []altNameRow {
+	return []altNameRow{
+		{"DNS Names", "DNS", mapStrings(names.DNSNames, formatDNSName)},
+		{"IP Addresses", "IP", mapStrings(names.IPAddresses, net.IP.String)},
+		{"Email Addresses", "Email", names.EmailAddresses},
+		{"URIs", "URI", mapStrings(names.URIs, formatURI)},
+		{"Directory Names", "Directory Name", mapStrings(names.DirectoryNames, dnFormat.formatInline)},
+		{"Registered IDs", "Registered ID", mapStrings(names.RegisteredIDs, func(oid asn1.ObjectIdentifier) string {
+			return certutil.OIDNameOrString(oid)
+		})},
+		{"Other Names", "Other Name", mapStrings(names.OtherNames, func(n certutil.OtherName) string {
+			return fmt.Sprintf("%s: %s", certutil.OIDNameOrString(n.Type), n.Value)
+		})},
+		{"Raw Names", "Raw Name", mapStrings(names.RawNames, func(n certutil.RawName) string {
+			return fmt.Sprintf("%s: %s", n.Type(), n.Value)
+		})},
+	}
+}
+
+func mapStrings[T any](values []T, format func(T) string) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = format(v)
+	}
+	return result
+}
+
+// formatDNSName shows internationalized domain names also in Unicode.
+func formatDNSName(name string) string {
+	if !certutil.IsIDN(name) {
+		return name
+	}
+
+	unicode, err := certutil.ToUnicode(name)
+	if err != nil {
+		return name + warningText.Sprintf(" (%s)", err)
+	}
+	return fmt.Sprintf("%s (%s)", name, unicode)
+}
+
+// formatURI breaks SPIFFE IDs down into the trust domain and the path.
+func formatURI(uri string) string {
+	id := certutil.ParseSPIFFEID(uri)
+	if id == nil {
+		return uri
+	}
+	return fmt.Sprintf("%s\n  Trust Domain: %s\n  Path: %s", uri, id.TrustDomain, id.Path)
+}
+
+// addExtensionRow adds a row showing the extension, marked when it is critical.
+func addExtensionRow(table *uitable.Table, c *Certificate, oid asn1.ObjectIdentifier, label, value string) {
+	if c.IsCriticalExtension(oid) {
+		label += " (critical)"
+	}
+	table.AddRow(label, value)
+}
+
+// formatASN1 returns a tree of ASN.1 values, or hex-encoded data when it is not valid DER.
+func formatASN1(der []byte) string {
+	tree, err := certutil.FormatASN1(der)
+	if err != nil {
+		return formatBytes(der) + warningText.Sprintf("\n%s", err)
+	}
+	return tree
+}
+
+// PrintFingerprint prints only a fingerprint of the certificate, for use in
+// scripts. Supported kinds are sha256, sha1 and spki.
+func (c *Certificate) PrintFingerprint(kind string) error {
+	switch kind {
+	case "sha256":
+		fmt.Println(formatFingerprint(c.Fingerprint(crypto.SHA256)))
+	case "sha1":
+		fmt.Println(formatFingerprint(c.Fingerprint(crypto.SHA1)))
+	case "spki":
+		fmt.Println(c.SPKIHash())
+	default:
+		return fmt.Errorf("unknown fingerprint %q", kind)
+	}
+	return nil
+}
+
+// formatFingerprint formats bytes as colon-separated hex, like OpenSSL does.
+func formatFingerprint(b []byte) string {
+	parts := make([]string, len(b))
+	for i, v := range b {
+		parts[i] = fmt.Sprintf("%02X", v)
+	}
+	return strings.Join(parts, ":")
+}
 
-	for i, v := range name.Names {
+// PrintCTSearchResults prints certificates found in CT logs.
+func PrintCTSearchResults(entries []CTSearchEntry, opts *PrintOptions) {
+	at := opts.currentTime()
+
+	table := uitable.New()
+	table.Separator = tableSeparator
+
+	table.AddRow("ID", "Names", "Issuer", "Not Valid Before", "Not Valid After", "Status")
+	for _, e := range entries {
+		status := e.ValidityStatus(at)
+		if status != "valid" {
+			status = warningText.Sprint(status)
+		}
+
+		table.AddRow(
+			e.ID,
+			strings.Join(e.Names(), ", "),
+			e.IssuerName,
+			e.NotBefore.Local().Format(time.DateTime),
+			e.NotAfter.Local().Format(time.DateTime),
+			status,
+		)
+	}
+
+	fmt.Println(table)
+}
+
+// PrintLintFindings prints lint findings grouped by certificate, followed
+// by a summary.
+func PrintLintFindings(c *Certificate, findings []LintFinding) {
+	counts := map[LintSeverity]int{}
+	for i, cert := range append([]*Certificate{c}, c.OrderedChain()...) {
 		if i > 0 {
-			b.WriteString("\n")
+			fmt.Print("\n")
 		}
+		fmt.Println(cert.CommonName())
 
-		t := v.Type.String()
-		if attrName, ok := attributeTypeNames[t]; ok {
-			b.WriteString(attrName)
-		} else {
-			b.WriteString(t)
+		table := uitable.New()
+		table.Wrap = true
+		table.Separator = tableSeparator
+		for _, f := range findings {
+			if f.Certificate != cert {
+				continue
+			}
+			counts[f.Rule.Severity]++
+			table.AddRow(formatLintSeverity(f.Rule.Severity), f.Rule.Name, fmt.Sprintf("%s\n%s", f.Message, f.Rule.Reference))
 		}
 
-		b.WriteByte('=')
-		b.WriteString(v.Value.(string))
+		if len(table.Rows) == 0 {
+			fmt.Println(greenText.Sprint("no findings"))
+			continue
+		}
+		fmt.Println(table)
 	}
-	return b.String()
+
+	fmt.Printf("\n%d findings: %d errors, %d warnings, %d notices\n", len(findings), counts[LintError], counts[LintWarning], counts[LintNotice])
+}
+
+func formatLintSeverity(s LintSeverity) string {
+	switch s {
+	case LintError:
+		return redText.Sprint(s)
+	case LintWarning:
+		return warningText.Sprint(s)
+	}
+	return s.String()
 }
 
 func indentText(text string, level int) string {
@@ -141,11 +465,22 @@ func indentText(text string, level int) string {
 }
 
 func formatBigInt(i *big.Int) string {
-	str := strings.ToUpper(i.Text(16))
+	str := i.Text(16)
 	if len(str)%2 == 1 {
 		str = "0" + str
 	}
 
+	return formatHex(str)
+}
+
+func formatBytes(b []byte) string {
+	return formatHex(hex.EncodeToString(b))
+}
+
+// formatHex splits hex-encoded data into bytes, 16 bytes per line.
+func formatHex(str string) string {
+	str = strings.ToUpper(str)
+
 	result := ""
 	for i := 0; i < len(str); i += 2 {
 		result += str[i : i+2]
@@ -159,12 +494,188 @@ func formatBigInt(i *big.Int) string {
 	return strings.TrimSuffix(result, "\n")
 }
 
-func certStatus(cert *Certificate) string {
-	revoked := false
-	if cert.IsOCSPPresent() {
-		ok, err := cert.OCSPStatus()
-		revoked = err == nil && !ok
+func formatPublicKey(info *certutil.PublicKeyInfo) string {
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("Algorithm: %s", info.Algorithm))
+	if info.Curve != "" {
+		b.WriteString(fmt.Sprintf("\nCurve: %s", info.Curve))
+	}
+	if info.Size > 0 {
+		b.WriteString(fmt.Sprintf("\nKey Size: %d bits", info.Size))
+	}
+	if info.Exponent > 0 {
+		b.WriteString(fmt.Sprintf("\nExponent: %d", info.Exponent))
+	}
+	if len(info.Data) > 0 {
+		label := "Public Key"
+		if info.Exponent > 0 {
+			label = "Modulus"
+		}
+		b.WriteString(fmt.Sprintf("\n%s:\n%s", label, indentText(formatBytes(info.Data), 1)))
+	}
+	for _, e := range info.Errors {
+		b.WriteString(redText.Sprintf("\nError: %s", e))
+	}
+	for _, w := range info.Warnings {
+		b.WriteString(redText.Sprintf("\nWarning: %s", w))
+	}
+	return b.String()
+}
+
+func formatQualifiedStatus(status *certutil.QualifiedStatus) string {
+	v := greenText.Sprint("yes, at issuance time") + warningText.Sprint(" (trusted list signatures not verified)")
+	if !status.Qualified {
+		v = redText.Sprintf("no: %s", status.Reason)
+	}
+
+	if status.Provider != "" {
+		v += fmt.Sprintf("\nTrusted List: %s\nProvider: %s\nService: %s", status.Territory, status.Provider, status.Service)
 	}
+	if status.Status != "" {
+		v += fmt.Sprintf("\nStatus at Issuance: %s", status.Status)
+	}
+	if len(status.Qualifiers) > 0 {
+		v += fmt.Sprintf("\nQualifiers: %s", strings.Join(status.Qualifiers, ", "))
+	}
+	return v
+}
+
+func formatPurpose(cert *Certificate, p Purpose) string {
+	mismatches := cert.PurposeMismatches(p)
+	if len(mismatches) == 0 {
+		return fmt.Sprintf("%s: OK", p)
+	}
+	return warningText.Sprintf("%s: mismatch\n%s", p, indentText(strings.Join(mismatches, "\n"), 1))
+}
+
+// expiryWarningPeriod defines how long before expiration a warning is shown.
+const expiryWarningPeriod = 30 * 24 * time.Hour
+
+func expiryWarning(cert *Certificate, at time.Time) string {
+	switch left := cert.NotAfter().Sub(at); {
+	case at.Before(cert.NotBefore()):
+		return warningText.Sprint("\n(not yet valid)")
+	case left < 0:
+		return warningText.Sprintf("\n(expired %s ago)", formatDays(-left))
+	case left < expiryWarningPeriod:
+		return warningText.Sprintf("\n(expires in %s)", formatDays(left))
+	}
+	return ""
+}
+
+func formatDays(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+func formatPrecertificateMatch(match bool, err error) string {
+	switch {
+	case err != nil:
+		return warningText.Sprint(err.Error())
+	case match:
+		return "matches the given precertificate"
+	default:
+		return redText.Sprint("does not match the given precertificate")
+	}
+}
+
+func formatPrecertificateSCT(status certutil.SCTStatus) string {
+	switch status {
+	case certutil.SCTVerified:
+		return "corresponds"
+	case certutil.SCTInvalid:
+		return redText.Sprint("does not correspond")
+	default:
+		return warningText.Sprint("unknown (log not in the log list)")
+	}
+}
+
+func formatInclusionProof(p *certutil.InclusionProof, err error) string {
+	if err != nil {
+		return redText.Sprint(err.Error())
+	}
+	return fmt.Sprintf("verified (leaf index %d, tree size %d)", p.LeafIndex, p.TreeSize)
+}
+
+func formatCTCompliance(results []*certutil.CTPolicyResult) string {
+	lines := []string{}
+	for _, r := range results {
+		switch {
+		case r.Compliant:
+			lines = append(lines, fmt.Sprintf("%s: pass", r.Policy))
+			continue
+		case r.Unknown:
+			lines = append(lines, warningText.Sprintf("%s: unknown", r.Policy))
+		default:
+			lines = append(lines, redText.Sprintf("%s: fail", r.Policy))
+		}
+		lines = append(lines, indentText(strings.Join(r.Reasons, "\n"), 1))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatOCSPStatus(status *certutil.OCSPStatus, err error) string {
+	if err != nil {
+		return warningText.Sprint(err.Error())
+	}
+
+	b := strings.Builder{}
+	switch {
+	case status.Revoked():
+		b.WriteString(redText.Sprintf("Status: %s\n", status.StatusString()))
+		b.WriteString(redText.Sprintf("Revoked on %s\n", status.RevocationTime.Local()))
+		b.WriteString(redText.Sprintf("Reason: %s\n", certutil.RevocationReasonString(status.Reason)))
+	case status.Good():
+		b.WriteString(fmt.Sprintf("Status: %s\n", status.StatusString()))
+	default:
+		b.WriteString(warningText.Sprintf("Status: %s\n", status.StatusString()))
+	}
+
+	if status.URL != "" {
+		b.WriteString(fmt.Sprintf("Responder: %s\n", status.URL))
+	}
+	if status.ResponderName != "" {
+		b.WriteString(fmt.Sprintf("Responder ID: %s\n", status.ResponderName))
+	} else if len(status.ResponderKeyHash) > 0 {
+		keyHash := big.NewInt(0).SetBytes(status.ResponderKeyHash)
+		b.WriteString(fmt.Sprintf("Responder ID (key hash):\n%s\n", indentText(formatBigInt(keyHash), 1)))
+	}
+	if status.Delegated {
+		b.WriteString("Signed by delegated responder\n")
+	}
+	b.WriteString(fmt.Sprintf("Produced At: %s\n", status.ProducedAt.Local()))
+	b.WriteString(fmt.Sprintf("This Update: %s", status.ThisUpdate.Local()))
+	if !status.NextUpdate.IsZero() {
+		b.WriteString(fmt.Sprintf("\nNext Update: %s", status.NextUpdate.Local()))
+	}
+	return b.String()
+}
+
+func formatCRLStatus(status *certutil.CRLStatus, err error) string {
+	if err != nil {
+		return warningText.Sprint(err.Error())
+	}
+
+	b := strings.Builder{}
+	if status.Revoked {
+		b.WriteString(redText.Sprintf("Revoked on %s\n", status.RevocationTime.Local()))
+		b.WriteString(redText.Sprintf("Reason: %s\n", certutil.RevocationReasonString(status.Reason)))
+	} else {
+		b.WriteString("Not revoked\n")
+	}
+	b.WriteString(fmt.Sprintf("Source: %s\n", status.URL))
+	b.WriteString(fmt.Sprintf("This Update: %s", status.ThisUpdate.Local()))
+	if !status.NextUpdate.IsZero() {
+		b.WriteString(fmt.Sprintf("\nNext Update: %s", status.NextUpdate.Local()))
+	}
+	return b.String()
+}
+
+func certStatus(cert *Certificate, revocation *RevocationStatus, vopts *VerifyOptions) string {
+	revoked := revocation.Revoked()
 
 	lBorder, rBorder := " ", " "
 
@@ -174,9 +685,11 @@ func certStatus(cert *Certificate) string {
 	}
 
 	switch {
+	case cert.IsPrecertificate():
+		return yellowBadge.Sprintf("%s PRECERT %s", lBorder, rBorder)
 	case revoked:
 		return redBadge.Sprintf("%s REVOKED %s", lBorder, rBorder)
-	case !cert.IsValid():
+	case !cert.IsValid(vopts):
 		return redBadge.Sprintf("%sNOT VALID%s", lBorder, rBorder)
 	default:
 		return greenBadge.Sprintf("%s  VALID  %s", lBorder, rBorder)
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+)
+
+// Purpose defines the intended use of a certificate.
+type Purpose string
+
+const (
+	PurposeServer    Purpose = "server"
+	PurposeClient    Purpose = "client"
+	PurposeCodeSign  Purpose = "codesign"
+	PurposeEmail     Purpose = "email"
+	PurposeTimestamp Purpose = "timestamp"
+	PurposeAny       Purpose = "any"
+)
+
+type purposeDescription struct {
+	extKeyUsage x509.ExtKeyUsage
+	// keyUsages lists key usages of which at least one must be asserted
+	// when the Key Usage extension is present.
+	keyUsages []x509.KeyUsage
+}
+
+var purposes = map[Purpose]purposeDescription{
+	PurposeServer: {
+		extKeyUsage: x509.ExtKeyUsageServerAuth,
+		keyUsages:   []x509.KeyUsage{x509.KeyUsageDigitalSignature, x509.KeyUsageKeyEncipherment, x509.KeyUsageKeyAgreement},
+	},
+	PurposeClient: {
+		extKeyUsage: x509.ExtKeyUsageClientAuth,
+		keyUsages:   []x509.KeyUsage{x509.KeyUsageDigitalSignature, x509.KeyUsageKeyAgreement},
+	},
+	PurposeCodeSign: {
+		extKeyUsage: x509.ExtKeyUsageCodeSigning,
+		keyUsages:   []x509.KeyUsage{x509.KeyUsageDigitalSignature},
+	},
+	PurposeEmail: {
+		extKeyUsage: x509.ExtKeyUsageEmailProtection,
+		keyUsages:   []x509.KeyUsage{x509.KeyUsageDigitalSignature, x509.KeyUsageContentCommitment, x509.KeyUsageKeyEncipherment, x509.KeyUsageKeyAgreement},
+	},
+	PurposeTimestamp: {
+		extKeyUsage: x509.ExtKeyUsageTimeStamping,
+		keyUsages:   []x509.KeyUsage{x509.KeyUsageDigitalSignature, x509.KeyUsageContentCommitment},
+	},
+	PurposeAny: {
+		extKeyUsage: x509.ExtKeyUsageAny,
+	},
+}
+
+// ParsePurpose returns a purpose with a given name.
+func ParsePurpose(name string) (Purpose, error) {
+	if _, ok := purposes[Purpose(name)]; !ok {
+		return "", fmt.Errorf("unknown purpose %q", name)
+	}
+	return Purpose(name), nil
+}
+
+// extKeyUsages returns extended key usages required by the purpose.
+// Empty purpose returns nil, which crypto/x509 treats as server
+// authentication.
+func (p Purpose) extKeyUsages() []x509.ExtKeyUsage {
+	if desc, ok := purposes[p]; ok {
+		return []x509.ExtKeyUsage{desc.extKeyUsage}
+	}
+	return nil
+}
+
+// PurposeMismatches returns reasons why the certificate cannot be used for
+// a given purpose. Empty result means the certificate fits the purpose.
+func (c *Certificate) PurposeMismatches(p Purpose) []string {
+	desc, ok := purposes[p]
+	if !ok {
+		return []string{fmt.Sprintf("unknown purpose %q", p)}
+	}
+
+	result := []string{}
+
+	if len(c.cert.ExtKeyUsage) > 0 || len(c.cert.UnknownExtKeyUsage) > 0 {
+		found := p == PurposeAny
+		for _, eku := range c.cert.ExtKeyUsage {
+			if eku == desc.extKeyUsage || eku == x509.ExtKeyUsageAny {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, fmt.Sprintf("Extended Key Usage does not include %s", desc.extKeyUsage))
+		}
+	}
+
+	// key usage of CA certificates is checked by chain verification
+	if c.cert.KeyUsage != 0 && !c.cert.IsCA && len(desc.keyUsages) > 0 {
+		found := false
+		names := make([]string, len(desc.keyUsages))
+		for i, ku := range desc.keyUsages {
+			names[i] = ku.String()
+			if c.cert.KeyUsage&ku != 0 {
+				found = true
+			}
+		}
+		if !found {
+			result = append(result, fmt.Sprintf("Key Usage does not include any of: %s", strings.Join(names, ", ")))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,82 @@
+package internal_test
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+)
+
+func TestPurposeMismatches(t *testing.T) {
+	cases := []struct {
+		name    string
+		modify  func(*x509.Certificate)
+		purpose internal.Purpose
+		want    []string
+		valid   bool
+	}{
+		{name: "server", purpose: internal.PurposeServer, want: []string{}, valid: true},
+		{name: "any", purpose: internal.PurposeAny, want: []string{}, valid: true},
+		{
+			name: "any with unknown EKUs only",
+			modify: func(c *x509.Certificate) {
+				c.ExtKeyUsage = nil
+				c.UnknownExtKeyUsage = []asn1.ObjectIdentifier{{1, 3, 6, 1, 4, 1, 99999, 1}}
+			},
+			purpose: internal.PurposeAny,
+			want:    []string{},
+			valid:   true,
+		},
+		{
+			name:    "client with server EKU",
+			purpose: internal.PurposeClient,
+			want:    []string{"Extended Key Usage does not include clientAuth"},
+		},
+		{
+			name: "client",
+			modify: func(c *x509.Certificate) {
+				c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+			},
+			purpose: internal.PurposeClient,
+			want:    []string{},
+			valid:   true,
+		},
+		{
+			name: "code signing without digital signature",
+			modify: func(c *x509.Certificate) {
+				c.KeyUsage = x509.KeyUsageKeyEncipherment
+				c.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}
+			},
+			purpose: internal.PurposeCodeSign,
+			want:    []string{"Key Usage does not include any of: digitalSignature"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tc := newTestChain(t, c.modify)
+			cert := tc.certificate()
+
+			if diff := cmp.Diff(c.want, cert.PurposeMismatches(c.purpose)); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+
+			opts := &internal.VerifyOptions{Roots: tc.roots(), Purpose: c.purpose}
+			if got := cert.IsValid(opts); got != c.valid {
+				t.Fatalf("IsValid() = %v, want %v", got, c.valid)
+			}
+		})
+	}
+}
+
+func TestParsePurpose(t *testing.T) {
+	if _, err := internal.ParsePurpose("foo"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if p, err := internal.ParsePurpose("email"); err != nil || p != internal.PurposeEmail {
+		t.Fatalf("ParsePurpose() = %q, %v", p, err)
+	}
+}
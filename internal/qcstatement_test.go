@@ -0,0 +1,132 @@
+package internal_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+func TestQCStatements(t *testing.T) {
+	type statement struct {
+		ID          asn1.ObjectIdentifier
+		Information asn1.RawValue `asn1:"optional,omitempty"`
+	}
+	info := func(v any) asn1.RawValue {
+		der, err := asn1.Marshal(v)
+		if err != nil {
+			t.Fatalf("cannot marshal information: %s", err)
+		}
+		return asn1.RawValue{FullBytes: der}
+	}
+	type monetaryValue struct {
+		Currency string `asn1:"printable"`
+		Amount   int
+		Exponent int
+	}
+	type semanticsInformation struct {
+		ID          asn1.ObjectIdentifier
+		Authorities []asn1.RawValue `asn1:"optional"`
+	}
+	type roleOfPSP struct {
+		ID   asn1.ObjectIdentifier
+		Name string `asn1:"utf8"`
+	}
+	type psd2 struct {
+		Roles   []roleOfPSP
+		NCAName string `asn1:"utf8"`
+		NCAID   string `asn1:"utf8"`
+	}
+
+	raName, err := asn1.Marshal(pkix.Name{Country: []string{"PL"}, Organization: []string{"Example RA"}}.ToRDNSequence())
+	if err != nil {
+		t.Fatalf("cannot marshal name: %s", err)
+	}
+
+	der, err := asn1.Marshal([]statement{
+		{ID: asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 1}},
+		{ID: asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 2}, Information: info(monetaryValue{"EUR", 15, -1})},
+		{ID: asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 3}, Information: info(7)},
+		{ID: asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 4}},
+		{ID: asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 7}, Information: info([]string{"PL", "DE"})},
+		{ID: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 11, 2}, Information: info(semanticsInformation{ID: asn1.ObjectIdentifier{0, 4, 0, 194121, 1, 2}})},
+		{ID: asn1.ObjectIdentifier{0, 4, 0, 19495, 2}, Information: info(psd2{
+			Roles: []roleOfPSP{
+				{asn1.ObjectIdentifier{0, 4, 0, 19495, 1, 2}, "PSP_PI"},
+				{asn1.ObjectIdentifier{0, 4, 0, 19495, 1, 3}, "PSP_AI"},
+			},
+			NCAName: "Polish Financial Supervision Authority",
+			NCAID:   "PL-PFSA",
+		})},
+		{ID: asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 6}, Information: info([]asn1.ObjectIdentifier{{0, 4, 0, 1862, 1, 6, 1}, {0, 4, 0, 1862, 1, 6, 2}})},
+		{ID: asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 6}, Information: info([]asn1.ObjectIdentifier{})},
+		{ID: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 11, 1}},
+		{ID: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 11, 2}, Information: info(semanticsInformation{
+			ID: asn1.ObjectIdentifier{0, 4, 0, 194121, 1, 1},
+			Authorities: []asn1.RawValue{
+				{Class: asn1.ClassContextSpecific, Tag: 6, Bytes: []byte("https://ra.example.com/")},
+				{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: raName},
+			},
+		})},
+	})
+	if err != nil {
+		t.Fatalf("cannot marshal statements: %s", err)
+	}
+
+	tc := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.ExtraExtensions = []pkix.Extension{{Id: certutil.OIDQCStatementsExt, Value: der}}
+	})
+	cert := internal.NewCertificate(tc.leaf)
+
+	want := "ETSI QcCompliance\n" +
+		"ETSI QcLimitValue = 1.5 EUR\n" +
+		"ETSI QcRetentionPeriod = 7 years\n" +
+		"ETSI QcSSCD\n" +
+		"ETSI QcCClegislation = PL, DE\n" +
+		"PKIX QCSyntax-v2 = ETSI Legal Person\n" +
+		"ETSI PSD2 = Roles: PSP_PI, PSP_AI, NCA: Polish Financial Supervision Authority (PL-PFSA)\n" +
+		"ETSI QcType = ETSI qct-esign, ETSI qct-eseal\n" +
+		"ETSI QcType = none\n" +
+		"PKIX QCSyntax-v1\n" +
+		"PKIX QCSyntax-v2 = ETSI Natural Person, Registration Authority: URI: https://ra.example.com/, Registration Authority: Directory Name: C=PL, O=Example RA"
+	if diff := cmp.Diff(want, cert.QCStatement(internal.DNMultiline)); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	got := cert.QCStatement(internal.DNOpenSSL)
+	if wantRA := "Registration Authority: Directory Name: /C=PL/O=Example RA"; !strings.HasSuffix(got, wantRA) {
+		t.Fatalf("QCStatement() = %q, want suffix %q", got, wantRA)
+	}
+
+	statements, err := cert.QCStatements()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	information, err := statements[6].Information()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	psd2Info, ok := information.(certutil.PSD2QcType)
+	if !ok {
+		t.Fatalf("Information() = %T, want certutil.PSD2QcType", information)
+	}
+	if diff := cmp.Diff([]string{"PSP_PI", "PSP_AI"}, []string{psd2Info.Roles[0].Name, psd2Info.Roles[1].Name}); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	information, _ = statements[7].Information()
+	if diff := cmp.Diff(certutil.QcType{{0, 4, 0, 1862, 1, 6, 1}, {0, 4, 0, 1862, 1, 6, 2}}, information); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	information, _ = statements[1].Information()
+	if code := information.(certutil.QcLimitValue).CurrencyCode(); code != "EUR" {
+		t.Fatalf("CurrencyCode() = %q, want %q", code, "EUR")
+	}
+}
@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+// RevocationMode defines which sources are used to check revocation status.
+type RevocationMode string
+
+const (
+	RevocationOCSP RevocationMode = "ocsp"
+	RevocationCRL  RevocationMode = "crl"
+	RevocationBoth RevocationMode = "both"
+	RevocationNone RevocationMode = "none"
+)
+
+// ParseRevocationMode returns a revocation mode with a given name.
+func ParseRevocationMode(name string) (RevocationMode, error) {
+	switch m := RevocationMode(name); m {
+	case RevocationOCSP, RevocationCRL, RevocationBoth, RevocationNone:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown revocation mode %q", name)
+}
+
+func (m RevocationMode) ocsp() bool {
+	return m == RevocationOCSP || m == RevocationBoth
+}
+
+func (m RevocationMode) crl() bool {
+	return m == RevocationCRL || m == RevocationBoth
+}
+
+// RevocationStatus defines revocation status of the certificate gathered
+// from all checked sources.
+type RevocationStatus struct {
+	OCSPChecked bool
+	// OCSP lists valid responses of all responders, OCSPErr reports
+	// responders which failed.
+	OCSP    []*certutil.OCSPStatus
+	OCSPErr error
+
+	CRLChecked bool
+	CRL        *certutil.CRLStatus
+	CRLErr     error
+
+	// Staple is the OCSP response stapled by the server, if any.
+	Staple    *certutil.OCSPStatus
+	StapleErr error
+
+	// MissingStaple reports whether the certificate requires OCSP stapling
+	// but the server did not staple a response.
+	MissingStaple bool
+}
+
+// Revoked reports whether any of the checked sources reported the
+// certificate as revoked.
+func (s *RevocationStatus) Revoked() bool {
+	ocspRevoked := false
+	for _, r := range s.OCSP {
+		ocspRevoked = ocspRevoked || r.Revoked()
+	}
+	crlRevoked := s.CRLChecked && s.CRLErr == nil && s.CRL.Revoked
+	stapleRevoked := s.Staple != nil && s.Staple.Revoked()
+	return ocspRevoked || crlRevoked || stapleRevoked
+}
+
+// StapleMismatches returns responses of responders which report
+// a different status than the stapled OCSP response.
+func (s *RevocationStatus) StapleMismatches() []*certutil.OCSPStatus {
+	result := []*certutil.OCSPStatus{}
+	if s.Staple == nil {
+		return result
+	}
+
+	for _, r := range s.OCSP {
+		if r.Status != s.Staple.Status {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// RevocationStatus checks revocation status of the certificate at a given
+// time using sources selected by mode. Zero time means now.
+func (c *Certificate) RevocationStatus(mode RevocationMode, at time.Time) *RevocationStatus {
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	status := &RevocationStatus{}
+
+	if mode.ocsp() && c.IsOCSPPresent() {
+		status.OCSPChecked = true
+		status.OCSP, status.OCSPErr = c.OCSPStatus(at)
+	}
+
+	if mode != RevocationNone {
+		if c.IsOCSPStapled() {
+			status.Staple, status.StapleErr = c.StapledOCSPStatus(at)
+		} else {
+			status.MissingStaple = c.servedOverTLS && c.MustStaple()
+		}
+	}
+
+	if mode.crl() && c.IsCRLPresent() {
+		status.CRLChecked = true
+		status.CRL, status.CRLErr = c.CRLStatus(at)
+	}
+
+	return status
+}
+
+// IsCRLPresent checks whether the CRL distribution point is present in the certificate.
+func (c *Certificate) IsCRLPresent() bool {
+	return len(c.cert.CRLDistributionPoints) > 0
+}
+
+// CRLStatus checks validity of the certificate at a given time with CRL
+// distribution points.
+func (c *Certificate) CRLStatus(at time.Time) (*certutil.CRLStatus, error) {
+	if !c.IsCRLPresent() {
+		return nil, fmt.Errorf("no CRL distribution point present for certificate")
+	}
+
+	issuer, issuerOk := c.chain[c.Issuer().String()]
+	if !issuerOk {
+		return nil, fmt.Errorf("issuer not present in chain")
+	}
+
+	return certutil.CheckCRL(c.cert, issuer.cert, at)
+}
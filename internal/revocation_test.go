@@ -0,0 +1,343 @@
+package internal_test
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestCRLStatus(t *testing.T) {
+	crlPath := filepath.Join(t.TempDir(), "test.crl")
+	tc := newTestChain(t, func(c *x509.Certificate) {
+		c.CRLDistributionPoints = []string{"file://" + crlPath}
+	})
+
+	writeCRL := func(nextUpdate time.Time, revoked ...x509.RevocationListEntry) {
+		der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+			Number:                    big.NewInt(1),
+			ThisUpdate:                nextUpdate.Add(-25 * time.Hour),
+			NextUpdate:                nextUpdate,
+			RevokedCertificateEntries: revoked,
+		}, tc.intermediate, tc.intermediateKey)
+		if err != nil {
+			t.Fatalf("cannot create CRL: %s", err)
+		}
+
+		data := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+		if err := os.WriteFile(crlPath, data, 0o600); err != nil {
+			t.Fatalf("cannot write CRL: %s", err)
+		}
+	}
+
+	t.Run("not revoked", func(t *testing.T) {
+		writeCRL(time.Now().Add(24*time.Hour), x509.RevocationListEntry{SerialNumber: big.NewInt(100), RevocationTime: time.Now()})
+
+		status := tc.certificate().RevocationStatus(internal.RevocationCRL, time.Time{})
+		if status.CRLErr != nil {
+			t.Fatalf("unexpected error: %s", status.CRLErr)
+		}
+		if status.Revoked() {
+			t.Fatal("expected certificate not to be revoked")
+		}
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		revokedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+		writeCRL(time.Now().Add(24*time.Hour), x509.RevocationListEntry{SerialNumber: tc.leaf.SerialNumber, RevocationTime: revokedAt, ReasonCode: 1})
+
+		status := tc.certificate().RevocationStatus(internal.RevocationBoth, time.Time{})
+		if status.CRLErr != nil {
+			t.Fatalf("unexpected error: %s", status.CRLErr)
+		}
+		if !status.Revoked() {
+			t.Fatal("expected certificate to be revoked")
+		}
+		if status.CRL.Reason != 1 || !status.CRL.RevocationTime.Equal(revokedAt) {
+			t.Fatalf("unexpected revocation details: %+v", status.CRL)
+		}
+
+		// the certificate was not revoked yet at an earlier verification time
+		status = tc.certificate().RevocationStatus(internal.RevocationCRL, revokedAt.Add(-time.Minute))
+		if status.CRLErr != nil {
+			t.Fatalf("unexpected error: %s", status.CRLErr)
+		}
+		if status.Revoked() {
+			t.Fatal("expected certificate not to be revoked before the revocation time")
+		}
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		nextUpdate := time.Now().Add(-time.Hour)
+		writeCRL(nextUpdate)
+
+		status := tc.certificate().RevocationStatus(internal.RevocationCRL, time.Time{})
+		want := fmt.Errorf("CRL %q is stale: next update was %s", "file://"+crlPath, nextUpdate.Truncate(time.Second).Local())
+		if diff := cmp.Diff(want, status.CRLErr, equateErrorMessage); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+
+		// the CRL was fresh at an earlier verification time
+		if status := tc.certificate().RevocationStatus(internal.RevocationCRL, nextUpdate.Add(-time.Minute)); status.CRLErr != nil {
+			t.Fatalf("unexpected error: %s", status.CRLErr)
+		}
+	})
+
+	t.Run("mode none", func(t *testing.T) {
+		if status := tc.certificate().RevocationStatus(internal.RevocationNone, time.Time{}); status.CRLChecked || status.Revoked() {
+			t.Fatalf("unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("signed by other issuer", func(t *testing.T) {
+		other := newTestChain(t, nil)
+		der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+			Number:     big.NewInt(1),
+			ThisUpdate: time.Now(),
+			NextUpdate: time.Now().Add(time.Hour),
+		}, other.intermediate, other.intermediateKey)
+		if err != nil {
+			t.Fatalf("cannot create CRL: %s", err)
+		}
+		if err := os.WriteFile(crlPath, der, 0o600); err != nil {
+			t.Fatalf("cannot write CRL: %s", err)
+		}
+
+		if status := tc.certificate().RevocationStatus(internal.RevocationCRL, time.Time{}); status.CRLErr == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestOCSPStatus(t *testing.T) {
+	var tc *testChain
+	methods := []string{}
+
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+
+		var body []byte
+		if r.Method == http.MethodGet {
+			body, _ = base64.StdEncoding.DecodeString(strings.TrimPrefix(r.URL.Path, "/"))
+		} else {
+			body, _ = io.ReadAll(r.Body)
+		}
+
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		template := ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Hour),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}
+		if req.SerialNumber.Cmp(tc.leaf.SerialNumber) == 0 {
+			template.Status = ocsp.Revoked
+			template.RevokedAt = time.Now().Add(-time.Minute)
+			template.RevocationReason = ocsp.KeyCompromise
+		}
+
+		resp, err := ocsp.CreateResponse(tc.intermediate, tc.intermediate, template, tc.intermediateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(resp)
+	}))
+	t.Cleanup(responder.Close)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(failing.Close)
+
+	tc = newTestChain(t, func(c *x509.Certificate) {
+		c.OCSPServer = []string{failing.URL, responder.URL}
+	})
+
+	status := tc.certificate().RevocationStatus(internal.RevocationOCSP, time.Time{})
+	if status.OCSPErr == nil {
+		t.Fatal("expected error of the failing responder, got nil")
+	}
+
+	if !status.Revoked() {
+		t.Fatal("expected certificate to be revoked")
+	}
+
+	if len(status.OCSP) != 1 {
+		t.Fatalf("expected one response, got %d", len(status.OCSP))
+	}
+	resp := status.OCSP[0]
+
+	if resp.URL != responder.URL || resp.Reason != ocsp.KeyCompromise || resp.Delegated {
+		t.Fatalf("unexpected OCSP status: %+v", resp)
+	}
+
+	if resp.ResponderName != "CN=Test Intermediate" {
+		t.Fatalf("unexpected responder name %q", resp.ResponderName)
+	}
+
+	if diff := cmp.Diff([]string{http.MethodGet}, methods); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	// the certificate was not revoked yet at an earlier verification time
+	status = tc.certificate().RevocationStatus(internal.RevocationOCSP, time.Now().Add(-time.Hour))
+	if status.Revoked() || len(status.OCSP) != 1 || !status.OCSP[0].Good() {
+		t.Fatalf("unexpected responses: %+v", status.OCSP)
+	}
+}
+
+func TestOCSPStatus_Errors(t *testing.T) {
+	var tc *testChain
+	nextUpdate := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(failing.Close)
+
+	stale := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := ocsp.CreateResponse(tc.intermediate, tc.intermediate, ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: tc.leaf.SerialNumber,
+			ThisUpdate:   nextUpdate.Add(-time.Hour),
+			NextUpdate:   nextUpdate,
+		}, tc.intermediateKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(resp)
+	}))
+	t.Cleanup(stale.Close)
+
+	tc = newTestChain(t, func(c *x509.Certificate) {
+		c.OCSPServer = []string{failing.URL, stale.URL}
+	})
+
+	req, err := ocsp.CreateRequest(tc.leaf, tc.intermediate, nil)
+	if err != nil {
+		t.Fatalf("cannot create OCSP request: %s", err)
+	}
+	getURL := failing.URL + "/" + url.QueryEscape(base64.StdEncoding.EncodeToString(req))
+
+	status := tc.certificate().RevocationStatus(internal.RevocationOCSP, time.Time{})
+	want := errors.Join(
+		fmt.Errorf("OCSP %q: got status code 503", getURL),
+		fmt.Errorf("OCSP %q: got status code 503", failing.URL),
+		fmt.Errorf("OCSP %q: response is stale: next update was %s", stale.URL, nextUpdate.Local()),
+	)
+	if diff := cmp.Diff(want, status.OCSPErr, equateErrorMessage); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+	if len(status.OCSP) != 0 {
+		t.Fatalf("unexpected responses: %+v", status.OCSP)
+	}
+
+	// the response was fresh at an earlier verification time
+	status = tc.certificate().RevocationStatus(internal.RevocationOCSP, nextUpdate.Add(-time.Minute))
+	if len(status.OCSP) != 1 || !status.OCSP[0].Good() {
+		t.Fatalf("unexpected responses: %+v", status.OCSP)
+	}
+}
+
+func TestStapledOCSPStatus(t *testing.T) {
+	createStaple := func(tc *testChain, status int, nextUpdate time.Time) []byte {
+		resp, err := ocsp.CreateResponse(tc.intermediate, tc.intermediate, ocsp.Response{
+			Status:       status,
+			SerialNumber: tc.leaf.SerialNumber,
+			ThisUpdate:   nextUpdate.Add(-2 * time.Hour),
+			NextUpdate:   nextUpdate,
+			RevokedAt:    time.Now().Add(-time.Minute),
+		}, tc.intermediateKey)
+		if err != nil {
+			t.Fatalf("cannot create OCSP response: %s", err)
+		}
+		return resp
+	}
+
+	mustStaple := pkix.Extension{Id: certutil.OIDTLSFeatureExt, Value: []byte{0x30, 0x03, 0x02, 0x01, 0x05}}
+
+	cases := []struct {
+		name          string
+		mustStaple    bool
+		staple        bool
+		stapleStatus  int
+		stale         bool
+		revoked       bool
+		missingStaple bool
+	}{
+		{name: "no staple"},
+		{name: "missing staple with Must-Staple", mustStaple: true, missingStaple: true},
+		{name: "good staple", mustStaple: true, staple: true, stapleStatus: ocsp.Good},
+		{name: "revoked staple", staple: true, stapleStatus: ocsp.Revoked, revoked: true},
+		{name: "stale staple", staple: true, stapleStatus: ocsp.Good, stale: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tc := newTestChain(t, func(leaf *x509.Certificate) {
+				if c.mustStaple {
+					leaf.ExtraExtensions = []pkix.Extension{mustStaple}
+				}
+			})
+
+			addr := startTLSServer(t, tc, func(cert *tls.Certificate) {
+				if c.staple {
+					nextUpdate := time.Now().Add(time.Hour)
+					if c.stale {
+						nextUpdate = time.Now().Add(-time.Hour)
+					}
+					cert.OCSPStaple = createStaple(tc, c.stapleStatus, nextUpdate)
+				}
+			})
+
+			cert, err := internal.GetCertificate(&url.URL{Scheme: "tcp", Host: addr})
+			if err != nil {
+				t.Fatalf("cannot get certificate: %s", err)
+			}
+
+			if cert.MustStaple() != c.mustStaple {
+				t.Fatalf("MustStaple() = %v, want %v", cert.MustStaple(), c.mustStaple)
+			}
+
+			status := cert.RevocationStatus(internal.RevocationOCSP, time.Time{})
+			if (status.StapleErr != nil) != c.stale {
+				t.Fatalf("unexpected error: %v", status.StapleErr)
+			}
+			if (status.Staple != nil) != (c.staple && !c.stale) {
+				t.Fatalf("unexpected staple: %+v", status.Staple)
+			}
+			if status.MissingStaple != c.missingStaple {
+				t.Fatalf("MissingStaple = %v, want %v", status.MissingStaple, c.missingStaple)
+			}
+			if status.Revoked() != c.revoked {
+				t.Fatalf("Revoked() = %v, want %v", status.Revoked(), c.revoked)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package internal_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+func TestAltNames(t *testing.T) {
+	mustMarshal := func(v any, params string) []byte {
+		der, err := asn1.MarshalWithParams(v, params)
+		if err != nil {
+			t.Fatalf("cannot marshal: %s", err)
+		}
+		return der
+	}
+
+	otherName := func(oid asn1.ObjectIdentifier, value []byte) []byte {
+		return mustMarshal(struct {
+			Type  asn1.ObjectIdentifier
+			Value asn1.RawValue
+		}{oid, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: value}}, "tag:0")
+	}
+	upn := otherName(certutil.OIDMicrosoftUPN, mustMarshal("jan@corp.example", "utf8"))
+	srv := otherName(certutil.OIDSRVName, mustMarshal("_xmpp.example.com", "ia5"))
+	unknown := otherName(asn1.ObjectIdentifier{1, 2, 3}, mustMarshal(7, ""))
+	rdn := pkix.Name{CommonName: "Jan", Organization: []string{"Corp"}}.ToRDNSequence()
+	dirName, err := certutil.ParseDN(mustMarshal(rdn, ""))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	san := mustMarshal([]asn1.RawValue{
+		{Class: asn1.ClassContextSpecific, Tag: 2, Bytes: []byte("xn--bcher-kva.example")},
+		{Class: asn1.ClassContextSpecific, Tag: 2, Bytes: []byte("example.com")},
+		{Class: asn1.ClassContextSpecific, Tag: 1, Bytes: []byte("jan@example.com")},
+		{Class: asn1.ClassContextSpecific, Tag: 6, Bytes: []byte("spiffe://example.org/ns/prod/sa/web")},
+		{Class: asn1.ClassContextSpecific, Tag: 7, Bytes: net.ParseIP("192.0.2.1").To4()},
+		{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: mustMarshal(rdn, "")},
+		{Class: asn1.ClassContextSpecific, Tag: 8, Bytes: mustMarshal(asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 8, 5}, "")[2:]},
+		{FullBytes: upn},
+		{FullBytes: srv},
+		{FullBytes: unknown},
+		{Class: asn1.ClassContextSpecific, Tag: 3, IsCompound: true, Bytes: []byte{0x30, 0x00}},
+		{Class: asn1.ClassContextSpecific, Tag: 5, IsCompound: true, Bytes: []byte{0xa1, 0x02, 0x13, 0x00}},
+		{Class: asn1.ClassContextSpecific, Tag: 9, Bytes: []byte{0x01}},
+	}, "")
+
+	tc := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.DNSNames = nil
+		leaf.ExtraExtensions = []pkix.Extension{{Id: certutil.OIDSubjectAltNameExt, Value: san}}
+	})
+	cert := internal.NewCertificate(tc.leaf)
+
+	got, err := cert.AltNames()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := &certutil.AltNames{
+		DNSNames:       []string{"xn--bcher-kva.example", "example.com"},
+		EmailAddresses: []string{"jan@example.com"},
+		URIs:           []string{"spiffe://example.org/ns/prod/sa/web"},
+		IPAddresses:    []net.IP{net.ParseIP("192.0.2.1").To4()},
+		DirectoryNames: []certutil.DN{dirName},
+		RegisteredIDs:  []asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 8, 5}},
+		OtherNames: []certutil.OtherName{
+			{Type: certutil.OIDMicrosoftUPN, Value: "jan@corp.example"},
+			{Type: certutil.OIDSRVName, Value: "_xmpp.example.com"},
+			{Type: asn1.ObjectIdentifier{1, 2, 3}, Value: "020107"},
+		},
+		RawNames: []certutil.RawName{
+			{Tag: 3, Value: "A3023000"},
+			{Tag: 5, Value: "A504A1021300"},
+			{Tag: 9, Value: "890101"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	types := []string{}
+	for _, n := range got.RawNames {
+		types = append(types, n.Type())
+	}
+	if diff := cmp.Diff([]string{"x400Address", "ediPartyName", "[9]"}, types); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(&certutil.SPIFFEID{TrustDomain: "example.org", Path: "/ns/prod/sa/web"}, certutil.ParseSPIFFEID(got.URIs[0])); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+		err  bool
+	}{
+		{name: "xn--bcher-kva.example", want: "bücher.example"},
+		{name: "www.xn--mnchen-3ya.de", want: "www.münchen.de"},
+		{name: "xn--fsq.xn--0zwm56d", want: "例.测试"},
+		{name: "example.com", want: "example.com"},
+		{name: "*.xn--bcher-kva.example", want: "*.bücher.example"},
+		{name: "xn--a-!.example", err: true},
+		{name: "xn--www-.example", err: true},
+		{name: "xn--a.example", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := certutil.ToUnicode(c.name)
+			if (err != nil) != c.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Fatalf("ToUnicode() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package internal_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+// trustedListXML returns a trusted list with a single service of the
+// certificate, whose status history is given by instances.
+func trustedListXML(cert *x509.Certificate, instances ...string) string {
+	history := ""
+	for _, instance := range instances[1:] {
+		history += "<ServiceHistoryInstance>" + instance + "</ServiceHistoryInstance>"
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<TrustServiceStatusList xmlns="http://uri.etsi.org/02231/v2#">
+  <SchemeInformation><SchemeTerritory>PL</SchemeTerritory></SchemeInformation>
+  <TrustServiceProviderList>
+    <TrustServiceProvider>
+      <TSPInformation><TSPName><Name xml:lang="en">Example TSP</Name></TSPName></TSPInformation>
+      <TSPServices>
+        <TSPService>
+          <ServiceInformation>
+            <ServiceName><Name xml:lang="en">Example Qualified CA</Name></ServiceName>
+            <ServiceDigitalIdentity><DigitalId><X509Certificate>%s</X509Certificate></DigitalId></ServiceDigitalIdentity>
+            %s
+          </ServiceInformation>
+          <ServiceHistory>%s</ServiceHistory>
+        </TSPService>
+      </TSPServices>
+    </TrustServiceProvider>
+  </TrustServiceProviderList>
+</TrustServiceStatusList>`, base64.StdEncoding.EncodeToString(cert.Raw), instances[0], history)
+}
+
+func serviceState(serviceType, status string, start time.Time, info ...string) string {
+	ext := ""
+	for _, i := range info {
+		ext += fmt.Sprintf("<Extension><AdditionalServiceInformation><URI>http://uri.etsi.org/TrstSvc/TrustedList/SvcInfoExt/%s</URI></AdditionalServiceInformation></Extension>", i)
+	}
+
+	return fmt.Sprintf(`<ServiceTypeIdentifier>http://uri.etsi.org/TrstSvc/Svctype/%s</ServiceTypeIdentifier>
+<ServiceStatus>http://uri.etsi.org/TrstSvc/TrustedList/Svcstatus/%s</ServiceStatus>
+<StatusStartingTime>%s</StatusStartingTime>
+<ServiceInformationExtensions>%s</ServiceInformationExtensions>`, serviceType, status, start.UTC().Format(time.RFC3339), ext)
+}
+
+// withQualifications adds Qualifications extensions to a service state.
+func withQualifications(state string, qualifications ...string) string {
+	ext := ""
+	for _, q := range qualifications {
+		ext += "<Extension><Qualifications>" + q + "</Qualifications></Extension>"
+	}
+	return strings.Replace(state, "</ServiceInformationExtensions>", ext+"</ServiceInformationExtensions>", 1)
+}
+
+func qualification(criteria string, qualifiers ...string) string {
+	q := ""
+	for _, qualifier := range qualifiers {
+		q += fmt.Sprintf(`<Qualifier uri="http://uri.etsi.org/TrstSvc/TrustedList/SvcInfoExt/%s"/>`, qualifier)
+	}
+	return fmt.Sprintf("<QualificationElement><Qualifiers>%s</Qualifiers>%s</QualificationElement>", q, criteria)
+}
+
+func TestCheckQualified(t *testing.T) {
+	qcStatements := func(qcType asn1.ObjectIdentifier) []byte {
+		type statement struct {
+			ID          asn1.ObjectIdentifier
+			Information []asn1.ObjectIdentifier `asn1:"optional,omitempty"`
+		}
+		der, err := asn1.Marshal([]statement{
+			{ID: asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 1}},
+			{ID: asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 6}, Information: []asn1.ObjectIdentifier{qcType}},
+		})
+		if err != nil {
+			t.Fatalf("cannot marshal statements: %s", err)
+		}
+		return der
+	}
+
+	qwac := newTestChain(t, func(leaf *x509.Certificate) {
+		leaf.Policies = []x509.OID{mustOID(t, "0.4.0.194112.1.4")}
+		leaf.ExtraExtensions = []pkix.Extension{{Id: certutil.OIDQCStatementsExt, Value: qcStatements(asn1.ObjectIdentifier{0, 4, 0, 1862, 1, 6, 3})}}
+	})
+	issued := qwac.leaf.NotBefore
+	past, future := issued.Add(-365*24*time.Hour), issued.Add(24*time.Hour)
+	qcpw := `<CriteriaList assert="all"><PolicySet><PolicyIdentifier><Identifier Qualifier="OIDAsURN">urn:oid:0.4.0.194112.1.4</Identifier></PolicyIdentifier></PolicySet></CriteriaList>`
+	digitalSignature := `<CriteriaList assert="atLeastOne"><KeyUsage><KeyUsageBit name="digitalSignature">true</KeyUsageBit></KeyUsage></CriteriaList>`
+	otherPolicy := `<CriteriaList assert="all"><PolicySet><PolicyIdentifier><Identifier>1.2.3</Identifier></PolicyIdentifier></PolicySet></CriteriaList>`
+
+	cases := []struct {
+		name      string
+		cert      *x509.Certificate
+		instances []string
+		want      *certutil.QualifiedStatus
+	}{
+		{
+			name:      "granted",
+			cert:      qwac.leaf,
+			instances: []string{serviceState("CA/QC", "granted", past, "ForWebSiteAuthentication")},
+			want:      &certutil.QualifiedStatus{Qualified: true, Territory: "PL", Provider: "Example TSP", Service: "Example Qualified CA", Status: "granted"},
+		},
+		{
+			name: "withdrawn after issuance",
+			cert: qwac.leaf,
+			instances: []string{
+				serviceState("CA/QC", "withdrawn", future, "ForWebSiteAuthentication"),
+				serviceState("CA/QC", "granted", past, "ForWebSiteAuthentication"),
+			},
+			want: &certutil.QualifiedStatus{Qualified: true, Territory: "PL", Provider: "Example TSP", Service: "Example Qualified CA", Status: "granted"},
+		},
+		{
+			name: "withdrawn before issuance",
+			cert: qwac.leaf,
+			instances: []string{
+				serviceState("CA/QC", "withdrawn", past.Add(time.Hour), "ForWebSiteAuthentication"),
+				serviceState("CA/QC", "granted", past, "ForWebSiteAuthentication"),
+			},
+			want: &certutil.QualifiedStatus{Reason: "the service status at issuance time was withdrawn", Territory: "PL", Provider: "Example TSP", Service: "Example Qualified CA", Status: "withdrawn"},
+		},
+		{
+			name:      "listed after issuance",
+			cert:      qwac.leaf,
+			instances: []string{serviceState("CA/QC", "granted", future)},
+			want:      &certutil.QualifiedStatus{Reason: "the service was not listed at issuance time", Territory: "PL", Provider: "Example TSP", Service: "Example Qualified CA"},
+		},
+		{
+			name:      "signatures only",
+			cert:      qwac.leaf,
+			instances: []string{serviceState("CA/QC", "granted", past, "ForeSignatures")},
+			want:      &certutil.QualifiedStatus{Reason: "the service was not qualified ForWebSiteAuthentication at issuance time", Territory: "PL", Provider: "Example TSP", Service: "Example Qualified CA", Status: "granted"},
+		},
+		{
+			name:      "not qualified by trusted list",
+			cert:      qwac.leaf,
+			instances: []string{withQualifications(serviceState("CA/QC", "granted", past, "ForWebSiteAuthentication"), qualification(qcpw, "NotQualified"))},
+			want:      &certutil.QualifiedStatus{Reason: "the trusted list qualifies the certificate as not qualified", Territory: "PL", Provider: "Example TSP", Service: "Example Qualified CA", Status: "granted", Qualifiers: []string{"NotQualified"}},
+		},
+		{
+			name:      "type assigned by trusted list",
+			cert:      qwac.leaf,
+			instances: []string{withQualifications(serviceState("CA/QC", "granted", past, "ForeSeals"), qualification(digitalSignature, "QCForESeal", "QCWithSSCD"))},
+			want:      &certutil.QualifiedStatus{Qualified: true, Territory: "PL", Provider: "Example TSP", Service: "Example Qualified CA", Status: "granted", Qualifiers: []string{"QCForESeal", "QCWithSSCD"}},
+		},
+		{
+			name:      "qualification criteria not met",
+			cert:      qwac.leaf,
+			instances: []string{withQualifications(serviceState("CA/QC", "granted", past, "ForWebSiteAuthentication"), qualification(otherPolicy, "NotQualified"))},
+			want:      &certutil.QualifiedStatus{Qualified: true, Territory: "PL", Provider: "Example TSP", Service: "Example Qualified CA", Status: "granted"},
+		},
+		{
+			name:      "not qualified",
+			cert:      newTestChain(t, nil).leaf,
+			instances: []string{serviceState("CA/QC", "granted", past)},
+			want:      &certutil.QualifiedStatus{Reason: "the certificate does not claim to be qualified (no QcCompliance statement)"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tl, err := certutil.ParseTrustedList([]byte(trustedListXML(qwac.intermediate, c.instances...)))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := certutil.CheckQualified(c.cert, []*certutil.TrustedList{tl})
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Fatalf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("not listed", func(t *testing.T) {
+		other := newTestChain(t, nil)
+		tl, err := certutil.ParseTrustedList([]byte(trustedListXML(other.intermediate, serviceState("CA/QC", "granted", past))))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		want := &certutil.QualifiedStatus{Reason: "the issuer is not listed in trusted lists"}
+		if diff := cmp.Diff(want, certutil.CheckQualified(qwac.leaf, []*certutil.TrustedList{tl})); diff != "" {
+			t.Fatalf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestLoadTrustedLists(t *testing.T) {
+	tc := newTestChain(t, nil)
+	dir := t.TempDir()
+
+	member := filepath.Join(dir, "pl.xml")
+	if err := os.WriteFile(member, []byte(trustedListXML(tc.intermediate, serviceState("CA/QC", "granted", time.Now()))), 0o600); err != nil {
+		t.Fatalf("cannot write trusted list: %s", err)
+	}
+
+	lotl := filepath.Join(dir, "lotl.xml")
+	content := fmt.Sprintf(`<TrustServiceStatusList>
+  <SchemeInformation>
+    <SchemeTerritory>EU</SchemeTerritory>
+    <TSLType>http://uri.etsi.org/TrstSvc/TrustedList/TSLType/EUlistofthelists</TSLType>
+    <PointersToOtherTSL>
+      <OtherTSLPointer>
+        <TSLLocation>%s</TSLLocation>
+        <AdditionalInformation><OtherInformation><MimeType>application/vnd.etsi.tsl+xml</MimeType></OtherInformation></AdditionalInformation>
+      </OtherTSLPointer>
+      <OtherTSLPointer>
+        <TSLLocation>%s</TSLLocation>
+        <AdditionalInformation><OtherInformation><MimeType>application/pdf</MimeType></OtherInformation></AdditionalInformation>
+      </OtherTSLPointer>
+      <OtherTSLPointer>
+        <TSLLocation>%s</TSLLocation>
+        <AdditionalInformation><OtherInformation><MimeType>application/vnd.etsi.tsl+xml</MimeType></OtherInformation></AdditionalInformation>
+      </OtherTSLPointer>
+    </PointersToOtherTSL>
+  </SchemeInformation>
+</TrustServiceStatusList>`, member, filepath.Join(dir, "pl.pdf"), filepath.Join(dir, "missing.xml"))
+	if err := os.WriteFile(lotl, []byte(content), 0o600); err != nil {
+		t.Fatalf("cannot write trusted list: %s", err)
+	}
+
+	lists, err := certutil.LoadTrustedLists(lotl)
+	if err == nil {
+		t.Fatalf("expected error for missing trusted list")
+	}
+
+	got := []string{}
+	for _, tl := range lists {
+		got = append(got, tl.Territory)
+	}
+	if diff := cmp.Diff([]string{"EU", "PL"}, got); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+	if n := len(lists[1].Providers[0].Services[0].Certificates); n != 1 {
+		t.Fatalf("got %d service certificates, want 1", n)
+	}
+
+	t.Run("member state list", func(t *testing.T) {
+		path := filepath.Join(dir, "de.xml")
+		content := fmt.Sprintf(`<TrustServiceStatusList>
+  <SchemeInformation>
+    <SchemeTerritory>DE</SchemeTerritory>
+    <TSLType>http://uri.etsi.org/TrstSvc/TrustedList/TSLType/EUgeneric</TSLType>
+    <PointersToOtherTSL>
+      <OtherTSLPointer>
+        <TSLLocation>%s</TSLLocation>
+        <AdditionalInformation><OtherInformation><MimeType>application/vnd.etsi.tsl+xml</MimeType></OtherInformation></AdditionalInformation>
+      </OtherTSLPointer>
+    </PointersToOtherTSL>
+  </SchemeInformation>
+</TrustServiceStatusList>`, lotl)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("cannot write trusted list: %s", err)
+		}
+
+		lists, err := certutil.LoadTrustedLists(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(lists) != 1 || lists[0].Territory != "DE" {
+			t.Fatalf("got %d lists, want only the DE list", len(lists))
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package internal_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
+)
+
+func TestIsROCAVulnerable(t *testing.T) {
+	// moduli of vulnerable keys are powers of 65537 modulo small primes,
+	// adding a multiple of all tested primes keeps residues unchanged
+	m := big.NewInt(1)
+	for _, p := range []int64{3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59, 61, 67, 71, 73, 79, 83, 89, 97, 101, 103, 107, 109, 113, 127, 131, 137, 139, 149, 151, 157, 163, 167} {
+		m.Mul(m, big.NewInt(p))
+	}
+	fingerprinted := new(big.Int).Exp(big.NewInt(65537), big.NewInt(12), nil)
+	fingerprinted.Add(fingerprinted, new(big.Int).Mul(m, big.NewInt(1<<40)))
+	if !certutil.IsROCAVulnerable(fingerprinted) {
+		t.Error("fingerprinted modulus not reported as vulnerable")
+	}
+
+	// zero is not a power of 65537 modulo 167
+	if certutil.IsROCAVulnerable(fingerprinted.Mul(fingerprinted, big.NewInt(167))) {
+		t.Error("modulus divisible by 167 reported as vulnerable")
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("cannot generate key: %s", err)
+	}
+	if certutil.IsROCAVulnerable(key.N) {
+		t.Error("generated key reported as vulnerable")
+	}
+}
+
+func TestSharedKeyFindings(t *testing.T) {
+	tc := newTestChain(t, nil)
+	other := newTestChain(t, nil)
+
+	reissued := *tc.leafTemplate
+	reissued.SerialNumber = big.NewInt(4)
+	reissued.DNSNames = []string{"www.example.com"}
+	reissued.Subject.CommonName = "www.example.com"
+
+	certs := []*internal.Certificate{
+		tc.certificate(),
+		internal.NewCertificate(tc.leaf),
+		internal.NewCertificate(signTestCert(t, &reissued, tc.intermediate, &tc.leafKey.PublicKey, tc.intermediateKey)),
+		other.certificate(),
+	}
+	targets := []string{"a.example:443", "b.example:443", "c.example:443", "d.example:443"}
+
+	got := []string{}
+	for _, f := range internal.SharedKeyFindings(targets, certs) {
+		got = append(got, fmt.Sprintf("%s: %s", f.Certificate.CommonName(), f.Message))
+	}
+
+	want := []string{
+		`example.com: shares the public key of "www.example.com" served by c.example:443`,
+		`example.com: shares the public key of "www.example.com" served by c.example:443`,
+		`www.example.com: shares the public key of "example.com" served by a.example:443`,
+		`www.example.com: shares the public key of "example.com" served by b.example:443`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("mismatch (-want +got):\n%s", diff)
+	}
+}
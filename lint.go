@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/spf13/pflag"
+)
+
+// lint checks certificate chains of given targets against lint rules and
+// exits with code 2 when any finding is at least as severe as --lint-fail-on.
+// Leaf certificates of different targets sharing a key are reported too.
+func lint() {
+	if pflag.NArg() < 2 {
+		pflag.Usage()
+		os.Exit(1)
+	}
+
+	failOn, err := internal.ParseLintSeverity(*fLintFailOn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid lint severity:", err)
+		os.Exit(1)
+	}
+
+	targets := pflag.Args()[1:]
+	certs := []*internal.Certificate{}
+	for _, target := range targets {
+		u, err := url.Parse(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to parse URL %s: %s\n", target, err)
+			os.Exit(1)
+		}
+
+		cert, err := internal.GetCertificate(u)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to get certificates of %s: %s\n", target, err)
+			os.Exit(1)
+		}
+
+		if !*fNoAIA {
+			cert.DownloadIssuingCertificate()
+		}
+		certs = append(certs, cert)
+	}
+
+	shared := internal.SharedKeyFindings(targets, certs)
+	failed := false
+	for i, cert := range certs {
+		if len(targets) > 1 {
+			if i > 0 {
+				fmt.Print("\n\n")
+			}
+			fmt.Printf("==> %s\n", targets[i])
+		}
+
+		findings := cert.Lint()
+		for _, f := range shared {
+			if f.Certificate == cert {
+				findings = append(findings, f)
+			}
+		}
+		internal.PrintLintFindings(cert, findings)
+
+		for _, f := range findings {
+			failed = failed || f.Rule.Severity >= failOn
+		}
+	}
+
+	if failed {
+		os.Exit(2)
+	}
+}
@@ -4,26 +4,87 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
+	ct "github.com/google/certificate-transparency-go"
 	"github.com/krzysdabro/tlscert/internal"
+	"github.com/krzysdabro/tlscert/internal/certutil"
 	"github.com/spf13/pflag"
 )
 
 var (
-	fNoChain = pflag.Bool("no-chain", false, "Do not show the chain of trust")
-	fNoAIA   = pflag.Bool("no-aia", false, "Do not follow AIA extension")
-	fNoSCT   = pflag.Bool("no-sct", false, "Do not print Signed Certificate Timestamps")
+	fNoChain     = pflag.Bool("no-chain", false, "Do not show the chain of trust")
+	fNoAIA       = pflag.Bool("no-aia", false, "Do not follow AIA extension")
+	fNoSCT       = pflag.Bool("no-sct", false, "Do not print Signed Certificate Timestamps")
+	fAt          = pflag.String("at", "", "Verify the certificate at a given time (RFC 3339, e.g. 2027-01-15T00:00:00Z)")
+	fIn          = pflag.String("in", "", "Verify the certificate after a given duration from now (e.g. 30d, 12h)")
+	fPurpose     = pflag.String("purpose", "", "Verify the certificate for a given purpose (server, client, codesign, email, timestamp, any)")
+	fRevocation  = pflag.String("revocation", "ocsp", "Check revocation status using given sources (ocsp, crl, both, none)")
+	fFingerprint = pflag.String("fingerprint", "", "Print only the certificate fingerprint (sha256, sha1, spki)")
+	fPins        = pflag.StringArray("pin", nil, "Require a certificate in the chain to match a public key pin (sha256/<base64>), can be repeated")
+	fPinFile     = pflag.String("pin-file", "", "Read public key pins from a file, one per line")
+	fBackupPins  = pflag.StringArray("backup-pin", nil, "Add a backup public key pin (sha256/<base64>) to generated pin sets, can be repeated")
+	fPinFormat   = pflag.String("pin-format", "", "Print only a pin set in a given format (android, ios, okhttp, hpkp)")
+	fPrecert     = pflag.String("precert", "", "Pair the certificate with a precertificate from a given URL to show corresponding SCTs")
+	fCTEndpoint  = pflag.String("ct-endpoint", internal.DefaultCTSearchEndpoint, "crt.sh-compatible API used by ct-search")
+	fCTInclusion = pflag.Bool("ct-verify-inclusion", false, "Verify SCTs are included in CT logs using inclusion proofs")
+	fCTLogURL    = pflag.String("ct-log-url", "", "Use a given CT log URL to verify inclusion proofs of SCTs from the log set by --ct-log-id or --ct-log-key instead of the log list")
+	fCTLogID     = pflag.String("ct-log-id", "", "Base64-encoded ID of the CT log at --ct-log-url")
+	fCTLogKey    = pflag.String("ct-log-key", "", "PEM or base64-encoded public key of the CT log at --ct-log-url, required for logs not in the log list")
+	fCTOpen      = pflag.Int64("ct-open", 0, "Print the certificate with a given ID found by ct-search")
+	fTrustedList = pflag.String("trusted-list", "", "Check qualified certificates against an ETSI trusted list from a given file or URL (\"eu\" for the EU list of trusted lists)")
+	fDNFormat    = pflag.String("dn-format", "multiline", "Print distinguished names in a given format (multiline, rfc4514, openssl)")
+	fLintFailOn  = pflag.String("lint-fail-on", "error", "Exit with code 2 when lint finds issues of a given or higher severity (notice, warning, error)")
+	fDebianKeys  = pflag.StringArray("debian-blocklist", nil, "Check RSA keys against a Debian weak key blocklist (openssl-blacklist format), can be repeated")
+	fOIDFile     = pflag.String("oid-file", "", "Read OID names from a file, one \"<oid> <name>\" per line (default: tlscert/oids in the user config directory)")
 )
 
 func main() {
 	pflag.Usage = usage
 	pflag.Parse()
 
+	if pflag.NArg() < 1 {
+		pflag.Usage()
+		os.Exit(1)
+	}
+
+	loadOIDFile(*fOIDFile)
+	for _, path := range *fDebianKeys {
+		if err := certutil.LoadDebianBlocklist(path); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid Debian blocklist:", err)
+			os.Exit(1)
+		}
+	}
+
+	switch pflag.Arg(0) {
+	case "ct-search":
+		rejectFlags("ct-search", "pin", "pin-file", "backup-pin")
+		ctSearch(printOptions())
+		return
+	case "pinset":
+		rejectFlags("pinset", "pin", "pin-file")
+		pinSet()
+		return
+	case "asn1":
+		rejectFlags("asn1", "pin", "pin-file", "backup-pin")
+		asn1Dump()
+		return
+	case "lint":
+		rejectFlags("lint", "pin", "pin-file", "backup-pin")
+		lint()
+		return
+	}
+	rejectFlags("certificate printing", "backup-pin")
+
 	if pflag.NArg() != 1 {
 		pflag.Usage()
 		os.Exit(1)
 	}
 
+	opts := printOptions()
+
 	arg := pflag.Arg(0)
 
 	u, err := url.Parse(arg)
@@ -38,13 +99,71 @@ func main() {
 		os.Exit(1)
 	}
 
-	if !*fNoAIA {
-		cert.DownloadIssuingCertificate()
+	if *fFingerprint != "" {
+		if err := cert.PrintFingerprint(*fFingerprint); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid fingerprint:", err)
+			os.Exit(1)
+		}
+	} else {
+		printCertificate(cert, opts)
+	}
+
+	if len(opts.Pins) > 0 {
+		checkPins(cert, opts.Pins)
+	}
+}
+
+// rejectFlags exits with an error when any of given flags, which the command
+// would ignore, is set.
+func rejectFlags(command string, names ...string) {
+	for _, name := range names {
+		if pflag.CommandLine.Changed(name) {
+			fmt.Fprintf(os.Stderr, "--%s is not supported by %s\n", name, command)
+			os.Exit(1)
+		}
+	}
+}
+
+// checkPins exits with an error when no certificate in the chain matches the pins.
+func checkPins(cert *internal.Certificate, pins []string) {
+	matched := cert.MatchPins(pins)
+	if len(matched) == 0 {
+		fmt.Fprintln(os.Stderr, "\nNo certificate in the chain matches the pins")
+		os.Exit(2)
 	}
 
-	opts := &internal.PrintOptions{
-		SCTs: !*fNoSCT,
+	for _, c := range matched {
+		fmt.Fprintf(os.Stderr, "\nPin matched: %s (sha256/%s)", c.CommonName(), c.SPKIHash())
 	}
+	fmt.Fprintln(os.Stderr)
+}
+
+// loadOIDFile registers OID names from a given file, or from the default
+// file in the user config directory when it exists.
+func loadOIDFile(path string) {
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return
+		}
+		path = filepath.Join(dir, "tlscert", "oids")
+		if _, err := os.Stat(path); err != nil {
+			return
+		}
+	}
+
+	if err := certutil.LoadOIDFile(path); err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid OID file:", err)
+		os.Exit(1)
+	}
+}
+
+// printCertificate prints the certificate followed by its chain.
+func printCertificate(cert *internal.Certificate, opts *internal.PrintOptions) {
+	if !*fNoAIA {
+		cert.DownloadIssuingCertificate()
+	}
+	opts.TrustedLists = loadTrustedLists(*fTrustedList)
 
 	cert.Print(opts)
 	if chain := cert.Chain(); !*fNoChain && len(chain) > 0 {
@@ -55,7 +174,174 @@ func main() {
 	}
 }
 
+// loadTrustedLists loads trusted lists from a given location, or returns nil
+// when it is empty.
+func loadTrustedLists(location string) []*certutil.TrustedList {
+	if location == "" {
+		return nil
+	}
+	if location == "eu" {
+		location = certutil.EUListOfTrustedLists
+	}
+
+	lists, err := certutil.LoadTrustedLists(location)
+	if err != nil {
+		if len(lists) == 0 {
+			fmt.Fprintln(os.Stderr, "Failed to load trusted list:", err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, "Warning: some trusted lists failed to load:", err)
+	}
+	return lists
+}
+
+// printOptions returns printing options set by flags.
+func printOptions() *internal.PrintOptions {
+	verifyTime, err := parseVerifyTime(*fAt, *fIn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid verification time:", err)
+		os.Exit(1)
+	}
+
+	var purpose internal.Purpose
+	if *fPurpose != "" {
+		if purpose, err = internal.ParsePurpose(*fPurpose); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid purpose:", err)
+			os.Exit(1)
+		}
+	}
+
+	revocation, err := internal.ParseRevocationMode(*fRevocation)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid revocation mode:", err)
+		os.Exit(1)
+	}
+
+	dnFormat, err := internal.ParseDNFormat(*fDNFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid distinguished name format:", err)
+		os.Exit(1)
+	}
+
+	var precert *internal.Certificate
+	if *fPrecert != "" {
+		u, err := url.Parse(*fPrecert)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to parse precertificate URL:", err)
+			os.Exit(1)
+		}
+
+		if precert, err = internal.GetCertificate(u); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to get precertificate:", err)
+			os.Exit(1)
+		}
+	}
+
+	pins := []string{}
+	for _, p := range *fPins {
+		pin, err := internal.ParsePin(p)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid pin:", err)
+			os.Exit(1)
+		}
+		pins = append(pins, pin)
+	}
+
+	if *fPinFile != "" {
+		filePins, err := internal.ParsePinFile(*fPinFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid pin file:", err)
+			os.Exit(1)
+		}
+		pins = append(pins, filePins...)
+	}
+
+	var ctLogID ct.SHA256Hash
+	var ctLogKey []byte
+	if *fCTLogURL != "" {
+		if *fCTLogID == "" && *fCTLogKey == "" {
+			fmt.Fprintln(os.Stderr, "Invalid CT log: --ct-log-url requires --ct-log-id or --ct-log-key")
+			os.Exit(1)
+		}
+		if *fCTLogKey != "" {
+			if ctLogKey, ctLogID, err = certutil.ParseLogKey(*fCTLogKey); err != nil {
+				fmt.Fprintln(os.Stderr, "Invalid CT log:", err)
+				os.Exit(1)
+			}
+		}
+		if *fCTLogID != "" {
+			id, err := certutil.ParseLogID(*fCTLogID)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Invalid CT log:", err)
+				os.Exit(1)
+			}
+			if ctLogKey != nil && id != ctLogID {
+				fmt.Fprintln(os.Stderr, "Invalid CT log: --ct-log-id does not match --ct-log-key")
+				os.Exit(1)
+			}
+			ctLogID = id
+		}
+	} else if *fCTLogKey != "" {
+		fmt.Fprintln(os.Stderr, "Invalid CT log: --ct-log-key requires --ct-log-url")
+		os.Exit(1)
+	}
+
+	return &internal.PrintOptions{
+		VerifyOptions: internal.VerifyOptions{
+			CurrentTime: verifyTime,
+			Purpose:     purpose,
+		},
+		SCTs:        !*fNoSCT,
+		Revocation:  revocation,
+		CTInclusion: *fCTInclusion,
+		CTLogURL:    *fCTLogURL,
+		CTLogID:     ctLogID,
+		CTLogKey:    ctLogKey,
+
+		Pins:           pins,
+		Precertificate: precert,
+		DNFormat:       dnFormat,
+	}
+}
+
+// parseVerifyTime returns the time at which certificates should be verified.
+// Zero time means the current time.
+func parseVerifyTime(at, in string) (time.Time, error) {
+	switch {
+	case at != "" && in != "":
+		return time.Time{}, fmt.Errorf("--at and --in cannot be used together")
+	case at != "":
+		return time.Parse(time.RFC3339, at)
+	case in != "":
+		d, err := parseDuration(in)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Now().Add(d), nil
+	}
+	return time.Time{}, nil
+}
+
+// parseDuration extends time.ParseDuration with days ("d") and weeks ("w").
+func parseDuration(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[s[len(s)-1]]; ok {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	return time.ParseDuration(s)
+}
+
 func usage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s [options] <url>\nOptions:\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Usage: %s [options] <url>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [options] ct-search <name>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [options] ct-search --ct-open <id>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [options] pinset <url>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [options] lint <url>...\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s asn1 <file>\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Options:")
 	pflag.PrintDefaults()
 }
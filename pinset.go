@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+
+	"github.com/krzysdabro/tlscert/internal"
+	"github.com/spf13/pflag"
+)
+
+// pinSet prints pinning configurations for the certificate chain.
+func pinSet() {
+	if pflag.NArg() != 2 {
+		pflag.Usage()
+		os.Exit(1)
+	}
+
+	formats := internal.PinSetFormats
+	if *fPinFormat != "" {
+		if !slices.Contains(internal.PinSetFormats, *fPinFormat) {
+			fmt.Fprintf(os.Stderr, "Invalid pin format: unknown pin set format %q\n", *fPinFormat)
+			os.Exit(1)
+		}
+		formats = []string{*fPinFormat}
+	}
+
+	backup := []string{}
+	for _, p := range *fBackupPins {
+		pin, err := internal.ParsePin(p)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid backup pin:", err)
+			os.Exit(1)
+		}
+		backup = append(backup, pin)
+	}
+
+	u, err := url.Parse(pflag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to parse URL:", err)
+		os.Exit(1)
+	}
+
+	cert, err := internal.GetCertificate(u)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to get certificates:", err)
+		os.Exit(1)
+	}
+
+	if !*fNoAIA {
+		cert.DownloadIssuingCertificate()
+	}
+
+	if len(backup) == 0 {
+		fmt.Fprintln(os.Stderr, "Warning: no backup pins given, losing the pinned keys will lock out clients")
+	}
+
+	ps := cert.PinSet(backup)
+	for i, format := range formats {
+		if i > 0 {
+			fmt.Print("\n\n")
+		}
+		out, _ := ps.Format(format)
+		fmt.Println(out)
+	}
+}